@@ -0,0 +1,4 @@
+# Compute total price with tax, rounded half-up to cents
+output "total_price" {
+  value = provider::hashicups::compute_tax_decimal("5.00", "0.085", "half-up", 2)
+}
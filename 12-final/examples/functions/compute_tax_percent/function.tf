@@ -0,0 +1,4 @@
+# Compute total price with an 8.5% tax rate
+output "total_price" {
+  value = provider::hashicups::compute_tax_percent(5.00, 8.5)
+}
@@ -0,0 +1,6 @@
+# Order as many coffees as fit within a budget of 20.00 with tax
+data "hashicups_coffees" "all" {}
+
+output "order_items" {
+  value = provider::hashicups::fit_budget(data.hashicups_coffees.all.coffees, 20.00, 0.085)
+}
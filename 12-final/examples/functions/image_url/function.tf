@@ -0,0 +1,4 @@
+# Resolve the image of a coffee against the HashiCups API host
+output "image_url" {
+  value = provider::hashicups::image_url("http://localhost:19090", "/hashicorp.png")
+}
@@ -0,0 +1,4 @@
+# Compute the cost of three coffees before tax
+output "line_total" {
+  value = provider::hashicups::line_total(2.15, 3)
+}
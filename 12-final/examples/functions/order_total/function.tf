@@ -0,0 +1,4 @@
+# Compute total price of an order with tax
+output "order_total" {
+  value = provider::hashicups::order_total([2, 1], [2.00, 1.00], 0.085)
+}
@@ -0,0 +1,4 @@
+# Parse an order ID before using it to import the order
+output "order_id" {
+  value = provider::hashicups::parse_order_id("123").id
+}
@@ -4,3 +4,15 @@ provider "hashicups" {
   password = "test123"
   host     = "http://localhost:19090"
 }
+
+# Credentials file-based authentication, reading the host, username and
+# password from the "staging" profile of ~/.hashicups/credentials:
+#
+#   [staging]
+#   host     = http://localhost:19090
+#   username = education
+#   password = test123
+provider "hashicups" {
+  alias   = "staging"
+  profile = "staging"
+}
@@ -0,0 +1,7 @@
+# Order can be imported by specifying its identity.
+import {
+  to = hashicups_order.example
+  identity = {
+    id = "123"
+  }
+}
@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxErrorBodySize limits how much of an error response body is kept.
+const maxErrorBodySize = 64 * 1024
+
+// apiError is returned for every HashiCups API response with a 4xx or 5xx
+// status, so callers can tell failures apart without parsing the message.
+type apiError struct {
+	StatusCode int
+	Method     string
+	Path       string
+	Body       string
+
+	// RequestID is the X-Request-Id header of the response, if any.
+	RequestID string
+}
+
+// Error implements error.
+func (e *apiError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s %s: status: %d", e.Method, e.Path, e.StatusCode)
+
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, ", request ID: %s", e.RequestID)
+	}
+
+	if e.Body != "" {
+		fmt.Fprintf(&b, ", body: %s", e.Body)
+	}
+
+	return b.String()
+}
+
+// details formats the error for diagnostics.
+func (e *apiError) details() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Request: %s %s\n", e.Method, e.Path)
+	fmt.Fprintf(&b, "Status: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, "\nRequest ID: %s", e.RequestID)
+	}
+
+	if e.Body != "" {
+		fmt.Fprintf(&b, "\nResponse Body: %s", e.Body)
+	}
+
+	return b.String()
+}
+
+// newAPIError returns the error for resp, a HashiCups API response with an
+// unsuccessful status, which was sent for req. Rate limited responses, which
+// the retry transport has given up retrying, return a *rateLimitedError. It
+// reads, but does not close, the response body.
+func newAPIError(req *http.Request, resp *http.Response) error {
+	if isRateLimited(resp) {
+		retryAfter, _ := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+		return &rateLimitedError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: retryAfter,
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil {
+		return err
+	}
+
+	return &apiError{
+		StatusCode: resp.StatusCode,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		Body:       strings.TrimSpace(string(body)),
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp-demoapp/hashicups-client-go"
+)
+
+func TestNewAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "abc123")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("order not found\n"))
+	}))
+	defer server.Close()
+
+	client := &hashicups.Client{
+		HostURL:    server.URL,
+		HTTPClient: newHTTPClient(httpClientConfig{}),
+	}
+
+	_, err := clientWithContext(context.Background(), client).GetOrder("42")
+
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected API error, got: %v", err)
+	}
+
+	expected := apiError{
+		StatusCode: http.StatusNotFound,
+		Method:     http.MethodGet,
+		Path:       "/orders/42",
+		Body:       "order not found",
+		RequestID:  "abc123",
+	}
+
+	if *apiErr != expected {
+		t.Errorf("expected %+v, got %+v", expected, *apiErr)
+	}
+}
+
+func TestNewHTTPClient_ErrorResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "order not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	// The transports must return error responses as is, as required by
+	// the http.RoundTripper contract.
+	resp, err := newHTTPClient(httpClientConfig{}).Get(server.URL + "/orders/42")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// authTransport keeps the requests of a HashiCups client signed in. It
+// sends requests with the current token of the session, and when the API
+// rejects a token with 401 Unauthorized, such as once it expires during a
+// long apply, it signs in again and retries the request once.
+type authTransport struct {
+	session  *session
+	host     string
+	username string
+	password string
+	next     http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, "/signin") {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+
+	// Clients keep the token they were created with, which is stale once
+	// any client of the session has signed in again.
+	token := t.session.currentToken()
+	if token != "" && req.Header.Get("Authorization") != token {
+		req = req.Clone(ctx)
+		req.Header.Set("Authorization", token)
+	} else {
+		token = req.Header.Get("Authorization")
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// A request body which cannot be replayed cannot be retried.
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	tflog.SubsystemInfo(ctx, apiLogSubsystem, "HashiCups API rejected the session token, signing in again", map[string]any{
+		"http_method": req.Method,
+		"http_path":   req.URL.Path,
+	})
+
+	token, signInErr := t.session.refresh(token, func() (string, error) {
+		client, err := newHashicupsClient(ctx, t.host, t.username, t.password, &http.Client{Transport: t.next})
+		if err != nil {
+			return "", err
+		}
+
+		return client.Token, nil
+	})
+	if signInErr != nil {
+		return nil, fmt.Errorf("signing in again after the session token was rejected: %w", signInErr)
+	}
+
+	// The request is retried only once, so a token which is rejected
+	// straight after signing in fails the request rather than looping.
+	req = req.Clone(ctx)
+	req.Header.Set("Authorization", token)
+
+	if req.GetBody != nil {
+		req.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestOrderResource_TokenExpired(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	created := server.create("hashicups_order", orderConfig(1, 2))
+	server.requireNoErrors(created.Diagnostics)
+
+	api.expireTokens()
+
+	read := server.read("hashicups_order", created.NewState, created.NewIdentity)
+	server.requireNoErrors(read.Diagnostics)
+
+	if got := api.requestCount("POST /signin"); got != 2 {
+		t.Errorf("expected 2 sign-ins, got %d", got)
+	}
+
+	if got := api.requestCount("GET /orders/1"); got != 2 {
+		t.Errorf("expected the rejected request to be retried once, got %d requests", got)
+	}
+
+	// Request bodies are sent again with the retried request.
+	api.expireTokens()
+
+	updated := server.update("hashicups_order", read.NewState, orderConfig(2, 3))
+	server.requireNoErrors(updated.Diagnostics)
+
+	if order, _ := api.order(1); len(order.Items) != 1 || order.Items[0].Quantity != 3 {
+		t.Errorf("expected order to be updated, got: %v", order.Items)
+	}
+}
+
+func TestOrderResource_TokenExpiredSharedSession(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	primary := newTestProviderServer(t, map[string]any{"host": api.URL()})
+	secondary := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	created := primary.create("hashicups_order", orderConfig(1, 2))
+	primary.requireNoErrors(created.Diagnostics)
+
+	api.expireTokens()
+
+	primary.requireNoErrors(primary.read("hashicups_order", created.NewState, created.NewIdentity).Diagnostics)
+
+	// The secondary provider uses the token the primary signed in for.
+	secondary.requireNoErrors(secondary.read("hashicups_order", created.NewState, created.NewIdentity).Diagnostics)
+
+	if got := api.requestCount("POST /signin"); got != 2 {
+		t.Errorf("expected 2 sign-ins, got %d", got)
+	}
+
+	if got := api.requestCount("GET /orders/1"); got != 3 {
+		t.Errorf("expected 3 order requests, got %d", got)
+	}
+}
+
+func TestOrderResource_TokenRejectedAfterSignIn(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	created := server.create("hashicups_order", orderConfig(1, 2))
+	server.requireNoErrors(created.Diagnostics)
+
+	api.inject(fakeAPIFault{
+		Method:     http.MethodGet,
+		Path:       "/orders/1",
+		StatusCode: http.StatusUnauthorized,
+		Body:       "Unauthorized",
+	})
+
+	resp := server.read("hashicups_order", created.NewState, created.NewIdentity)
+	server.requireError(resp.Diagnostics, "HashiCups API Authentication Failed")
+
+	if got := api.requestCount("POST /signin"); got != 2 {
+		t.Errorf("expected a single sign-in after the rejected request, got %d sign-ins", got)
+	}
+
+	if got := api.requestCount("GET /orders/1"); got != 2 {
+		t.Errorf("expected the rejected request to be retried once, got %d requests", got)
+	}
+}
+
+func TestOrderResource_TokenExpiredSignInFails(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	created := server.create("hashicups_order", orderConfig(1, 2))
+	server.requireNoErrors(created.Diagnostics)
+
+	api.expireTokens()
+	api.inject(fakeAPIFault{
+		Method:     http.MethodPost,
+		Path:       "/signin",
+		StatusCode: http.StatusUnauthorized,
+		Body:       "Password changed",
+	})
+
+	resp := server.read("hashicups_order", created.NewState, created.NewIdentity)
+	server.requireError(resp.Diagnostics, "HashiCups API Authentication Failed")
+}
+
+func TestAuthTransport_Concurrent(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	registry := newSessionRegistry()
+
+	client, err := registry.client(context.Background(), api.URL(), fakeAPIUsername, fakeAPIPassword, httpClientConfig{}, newHTTPClient(httpClientConfig{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	api.expireTokens()
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 10)
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := clientWithContext(context.Background(), client).GetCoffees()
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	}
+
+	if got := api.requestCount("POST /signin"); got != 2 {
+		t.Errorf("expected a single sign-in after the tokens expired, got %d sign-ins", got)
+	}
+}
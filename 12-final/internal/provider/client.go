@@ -0,0 +1,350 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/hashicorp-demoapp/hashicups-client-go"
+)
+
+// httpClientConfig holds the provider settings which influence how HTTP
+// requests are sent to the HashiCups API.
+type httpClientConfig struct {
+	// ProxyURL routes all requests through the given proxy. When nil, the
+	// standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+	// are honoured.
+	ProxyURL *url.URL
+
+	// Headers are added to every request.
+	Headers map[string]string
+
+	// UserAgent replaces the default Go User-Agent header.
+	UserAgent string
+
+	// RequestsPerSecond limits the rate at which requests are sent. Zero
+	// disables rate limiting.
+	RequestsPerSecond float64
+
+	// MaxConcurrentRequests limits the number of requests in flight at any
+	// time. Zero disables the limit.
+	MaxConcurrentRequests int
+}
+
+// reservedHeaders are the headers the provider sets itself, which the
+// custom headers must not replace, with how to change them instead, if at
+// all. Names are in canonical form.
+var reservedHeaders = map[string]string{
+	"Authorization":  "Set the username and password values instead.",
+	"Content-Length": "",
+	"Content-Type":   "",
+	"Host":           "Set the host value instead.",
+	"Traceparent":    "",
+	"Tracestate":     "",
+	"User-Agent":     "Set the user_agent value instead.",
+}
+
+// newHTTPClient creates the HTTP client used by the HashiCups API client.
+// The returned client is shared by every resource and data source, so any
+// limits it enforces apply to the provider as a whole.
+func newHTTPClient(config httpClientConfig) *http.Client {
+	// The connection pool is shared with every other client of the process
+	// using the same proxy.
+	transport := transports.transport(config.ProxyURL)
+
+	// The timeout is applied per request, after any throttling, so time
+	// spent waiting for the rate limiter or a Retry-After delay is not
+	// counted against it.
+	return &http.Client{
+		Transport: &headerTransport{
+			headers:   config.Headers,
+			userAgent: config.UserAgent,
+			next: &tracingTransport{
+				next: &loggingTransport{
+					next: &retryTransport{
+						budget: defaultRetryBudget,
+						next: newThrottleTransport(config.RequestsPerSecond, config.MaxConcurrentRequests, &timeoutTransport{
+							timeout: 10 * time.Second,
+							next:    transport,
+						}),
+					},
+				},
+			},
+		},
+	}
+}
+
+// newHashicupsClient signs in to the HashiCups API using the given HTTP
+// client. It mirrors hashicups.NewClient, which does not allow the HTTP
+// client to be replaced before signing in.
+func newHashicupsClient(ctx context.Context, host, username, password string, httpClient *http.Client) (*hashicups.Client, error) {
+	client := &hashicups.Client{
+		HostURL:    host,
+		HTTPClient: httpClient,
+		Auth: hashicups.AuthStruct{
+			Username: username,
+			Password: password,
+		},
+	}
+
+	ar, err := clientWithContext(ctx, client).SignIn()
+	if err != nil {
+		return nil, err
+	}
+
+	client.Token = ar.Token
+
+	return client, nil
+}
+
+// checkHealth sends a lightweight request to the HashiCups API, so an
+// unreachable host is reported as such rather than as a failed sign-in.
+// Any response, whatever its status, shows the API is reachable.
+func checkHealth(ctx context.Context, host string, httpClient *http.Client) error {
+	req, err := http.NewRequestWithContext(withAPILogSubsystem(ctx), http.MethodGet, host+"/health", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+
+	return nil
+}
+
+// apiClient sends the requests of a HashiCups client with the context of a
+// Terraform operation. It mirrors the methods of the HashiCups client, whose
+// errors do not say which status the API responded with, and returns an
+// *apiError for each unsuccessful response instead, or a *rateLimitedError
+// for rate limited ones. The transports of the HTTP client return every
+// response as is, as the http.RoundTripper contract requires.
+type apiClient struct {
+	ctx    context.Context
+	client *hashicups.Client
+}
+
+// clientWithContext returns an apiClient which sends the requests of client
+// with ctx. The HashiCups client does not accept a context, so this is how
+// the transports receive the tflog logger and cancellation of the Terraform
+// operation that made the request. The transports log to the api
+// subsystem.
+func clientWithContext(ctx context.Context, client *hashicups.Client) *apiClient {
+	return &apiClient{
+		ctx:    withAPILogSubsystem(ctx),
+		client: client,
+	}
+}
+
+// SignIn returns a new token for the username and password of the client.
+func (c *apiClient) SignIn() (*hashicups.AuthResponse, error) {
+	if c.client.Auth.Username == "" || c.client.Auth.Password == "" {
+		return nil, errors.New("define username and password")
+	}
+
+	body, err := c.doRequest(http.MethodPost, "/signin", c.client.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	ar := hashicups.AuthResponse{}
+
+	err = json.Unmarshal(body, &ar)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ar, nil
+}
+
+// GetCoffees returns the coffee catalog.
+func (c *apiClient) GetCoffees() ([]hashicups.Coffee, error) {
+	body, err := c.doRequest(http.MethodGet, "/coffees", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	coffees := []hashicups.Coffee{}
+
+	err = json.Unmarshal(body, &coffees)
+	if err != nil {
+		return nil, err
+	}
+
+	return coffees, nil
+}
+
+// ListOrders returns every order of the signed in user. The HashiCups
+// client has no method for listing orders.
+func (c *apiClient) ListOrders() ([]hashicups.Order, error) {
+	body, err := c.doRequest(http.MethodGet, "/orders", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := []hashicups.Order{}
+
+	err = json.Unmarshal(body, &orders)
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// GetOrder returns the order with the given ID.
+func (c *apiClient) GetOrder(orderID string) (*hashicups.Order, error) {
+	return c.orderRequest(http.MethodGet, "/orders/"+orderID, nil)
+}
+
+// CreateOrder creates an order of orderItems.
+func (c *apiClient) CreateOrder(orderItems []hashicups.OrderItem) (*hashicups.Order, error) {
+	return c.orderRequest(http.MethodPost, "/orders", orderItems)
+}
+
+// UpdateOrder replaces the items of the order with the given ID.
+func (c *apiClient) UpdateOrder(orderID string, orderItems []hashicups.OrderItem) (*hashicups.Order, error) {
+	return c.orderRequest(http.MethodPut, "/orders/"+orderID, orderItems)
+}
+
+// DeleteOrder deletes the order with the given ID.
+func (c *apiClient) DeleteOrder(orderID string) error {
+	body, err := c.doRequest(http.MethodDelete, "/orders/"+orderID, nil)
+	if err != nil {
+		return err
+	}
+
+	if string(body) != "Deleted order" {
+		return errors.New(string(body))
+	}
+
+	return nil
+}
+
+// orderRequest sends a request which responds with an order.
+func (c *apiClient) orderRequest(method, path string, requestBody any) (*hashicups.Order, error) {
+	body, err := c.doRequest(method, path, requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	order := hashicups.Order{}
+
+	err = json.Unmarshal(body, &order)
+	if err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// doRequest sends a request with requestBody, if any, encoded as JSON, and
+// returns the body of a successful response.
+func (c *apiClient) doRequest(method, path string, requestBody any) ([]byte, error) {
+	var reader io.Reader
+
+	if requestBody != nil {
+		rb, err := json.Marshal(requestBody)
+		if err != nil {
+			return nil, err
+		}
+
+		reader = bytes.NewReader(rb)
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, method, c.client.HostURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", c.client.Token)
+
+	res, err := c.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return nil, newAPIError(req, res)
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+// headerTransport sets the User-Agent and any custom headers on each
+// request before passing it to the next transport.
+type headerTransport struct {
+	headers   map[string]string
+	userAgent string
+	next      http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrip must not modify the original request.
+	req = req.Clone(req.Context())
+
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+
+	if t.userAgent != "" {
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+// timeoutTransport bounds the time taken by each request, including reading
+// the response body.
+type timeoutTransport struct {
+	timeout time.Duration
+	next    http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = newCloseFuncBody(resp.Body, cancel)
+
+	return resp, nil
+}
+
+// closeFuncBody calls onClose once the response body has been closed.
+type closeFuncBody struct {
+	io.ReadCloser
+	once    sync.Once
+	onClose func()
+}
+
+func newCloseFuncBody(body io.ReadCloser, onClose func()) *closeFuncBody {
+	return &closeFuncBody{
+		ReadCloser: body,
+		onClose:    onClose,
+	}
+}
+
+// Close implements io.Closer.
+func (b *closeFuncBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.onClose)
+
+	return err
+}
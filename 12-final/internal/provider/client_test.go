@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestNewHTTPClient_Headers(t *testing.T) {
+	var got http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	client := newHTTPClient(httpClientConfig{
+		Headers: map[string]string{
+			"X-Tenant": "cafe",
+		},
+		UserAgent: "terraform-provider-hashicups/test",
+	})
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if value := got.Get("X-Tenant"); value != "cafe" {
+		t.Errorf("expected X-Tenant header %q, got %q", "cafe", value)
+	}
+
+	if value := got.Get("User-Agent"); value != "terraform-provider-hashicups/test" {
+		t.Errorf("expected User-Agent header %q, got %q", "terraform-provider-hashicups/test", value)
+	}
+}
+
+func TestNewHTTPClient_ProxyURL(t *testing.T) {
+	var proxied string
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	client := newHTTPClient(httpClientConfig{
+		ProxyURL: proxyURL,
+	})
+
+	resp, err := client.Get("http://hashicups.invalid/coffees")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if proxied != "http://hashicups.invalid/coffees" {
+		t.Errorf("expected request to be sent through proxy, got %q", proxied)
+	}
+}
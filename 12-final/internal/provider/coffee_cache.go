@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hashicorp-demoapp/hashicups-client-go"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// coffeeCache holds the coffee catalog for a provider instance. The catalog
+// rarely changes during a Terraform run, while every hashicups_coffees data
+// source, and the plan of every hashicups_order, would otherwise fetch it
+// again.
+type coffeeCache struct {
+	// ttl is how long a fetched catalog is reused. Zero disables caching.
+	ttl time.Duration
+
+	// mu is held while fetching, so concurrent misses result in a single
+	// request.
+	mu      sync.Mutex
+	coffees []hashicups.Coffee
+	expires time.Time
+}
+
+func newCoffeeCache(ttl time.Duration) *coffeeCache {
+	return &coffeeCache{
+		ttl: ttl,
+	}
+}
+
+// enabled reports whether the catalog is cached.
+func (c *coffeeCache) enabled() bool {
+	return c != nil && c.ttl > 0
+}
+
+// GetCoffees returns the cached catalog, fetching it with client when the
+// cache is disabled, empty or expired. The returned slice must not be
+// modified.
+func (c *coffeeCache) GetCoffees(ctx context.Context, client *hashicups.Client) ([]hashicups.Coffee, error) {
+	if c.ttl == 0 {
+		return clientWithContext(ctx, client).GetCoffees()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.coffees != nil && time.Now().Before(c.expires) {
+		tflog.Debug(ctx, "HashiCups coffee cache hit", map[string]any{
+			"cache_expires": c.expires.Format(time.RFC3339),
+		})
+
+		return c.coffees, nil
+	}
+
+	tflog.Debug(ctx, "HashiCups coffee cache miss", map[string]any{
+		"cache_ttl": c.ttl.String(),
+	})
+
+	coffees, err := clientWithContext(ctx, client).GetCoffees()
+	if err != nil {
+		return nil, err
+	}
+
+	c.coffees = coffees
+	c.expires = time.Now().Add(c.ttl)
+
+	return coffees, nil
+}
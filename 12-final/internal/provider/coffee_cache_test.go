@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp-demoapp/hashicups-client-go"
+)
+
+func TestCoffeeCache(t *testing.T) {
+	testCases := map[string]struct {
+		ttl              time.Duration
+		expectedRequests int
+	}{
+		"disabled": {
+			ttl:              0,
+			expectedRequests: 2,
+		},
+		"enabled": {
+			ttl:              time.Minute,
+			expectedRequests: 1,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var requests int
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				_, _ = w.Write([]byte(`[{"id":1,"name":"HCP Aeropress"}]`))
+			}))
+			defer server.Close()
+
+			client := &hashicups.Client{
+				HostURL:    server.URL,
+				HTTPClient: newHTTPClient(httpClientConfig{}),
+			}
+			cache := newCoffeeCache(testCase.ttl)
+
+			for i := 0; i < 2; i++ {
+				coffees, err := cache.GetCoffees(context.Background(), client)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if len(coffees) != 1 || coffees[0].Name != "HCP Aeropress" {
+					t.Errorf("unexpected coffees: %+v", coffees)
+				}
+			}
+
+			if requests != testCase.expectedRequests {
+				t.Errorf("expected %d requests, got %d", testCase.expectedRequests, requests)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -23,11 +24,13 @@ func NewCoffeesDataSource() datasource.DataSource {
 
 // coffeesDataSource is the data source implementation.
 type coffeesDataSource struct {
-	client *hashicups.Client
+	client  *hashicups.Client
+	coffees *coffeeCache
 }
 
 // coffeesDataSourceModel maps the data source schema data.
 type coffeesDataSourceModel struct {
+	ID      types.String   `tfsdk:"id"`
 	Coffees []coffeesModel `tfsdk:"coffees"`
 }
 
@@ -113,12 +116,13 @@ func (d *coffeesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest
 func (d *coffeesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var state coffeesDataSourceModel
 
-	coffees, err := d.client.GetCoffees()
+	coffees, err := d.coffees.GetCoffees(ctx, d.client)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(clientErrorDiagnostic(
+			err,
 			"Unable to Read HashiCups Coffees",
 			err.Error(),
-		)
+		))
 		return
 	}
 
@@ -142,6 +146,13 @@ func (d *coffeesDataSource) Read(ctx context.Context, req datasource.ReadRequest
 		state.Coffees = append(state.Coffees, coffeeState)
 	}
 
+	// Terraform requires data sources to have an id attribute
+	state.ID = types.StringValue("placeholder")
+
+	tflog.Debug(ctx, "Read HashiCups coffees", map[string]any{
+		"coffee_count": len(state.Coffees),
+	})
+
 	// Set state
 	diags := resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -158,15 +169,16 @@ func (d *coffeesDataSource) Configure(_ context.Context, req datasource.Configur
 		return
 	}
 
-	client, ok := req.ProviderData.(*hashicups.Client)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *hashicups.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	d.client = client
+	d.client = data.client
+	d.coffees = data.coffees
 }
@@ -1,12 +1,21 @@
 package provider
 
 import (
+	"net/http"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-testing/compare"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
 )
 
 func TestAccCoffeesDataSource(t *testing.T) {
+	// coffeesSame verifies the coffees do not change between steps.
+	coffeesSame := statecheck.CompareValue(compare.ValuesSame())
+
 	resource.Test(t, resource.TestCase{
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
@@ -26,7 +35,60 @@ func TestAccCoffeesDataSource(t *testing.T) {
 					resource.TestCheckResourceAttr("data.hashicups_coffees.test", "coffees.0.price", "200"),
 					resource.TestCheckResourceAttr("data.hashicups_coffees.test", "coffees.0.teaser", "Automation in a cup"),
 				),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.hashicups_coffees.test", tfjsonpath.New("id"), knownvalue.StringExact("placeholder")),
+					coffeesSame.AddStateValue("data.hashicups_coffees.test", tfjsonpath.New("coffees")),
+				},
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PostApplyPostRefresh: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+			// Verify reading again returns the same coffees
+			{
+				Config: providerConfig + `data "hashicups_coffees" "test" {}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					coffeesSame.AddStateValue("data.hashicups_coffees.test", tfjsonpath.New("coffees")),
+				},
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
 			},
 		},
 	})
 }
+
+func TestCoffeesDataSource_Read(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+	stateType := server.dataSourceType("hashicups_coffees")
+
+	resp := server.readDataSource("hashicups_coffees", map[string]any{})
+	server.requireNoErrors(resp.Diagnostics)
+
+	if got := server.stringAttribute(stateType, resp.State, "id"); got != "placeholder" {
+		t.Errorf("expected id %q, got %q", "placeholder", got)
+	}
+
+	if got := server.stringAttribute(stateType, resp.State, "coffees", 2, "name"); got != "Vaulatte" {
+		t.Errorf("expected coffee name %q, got %q", "Vaulatte", got)
+	}
+}
+
+func TestCoffeesDataSource_ReadAPIError(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	api.inject(fakeAPIFault{
+		Method:     http.MethodGet,
+		Path:       "/coffees",
+		StatusCode: http.StatusInternalServerError,
+		Body:       "database unavailable",
+	})
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	resp := server.readDataSource("hashicups_coffees", map[string]any{})
+
+	server.requireError(resp.Diagnostics, "Unable to Read HashiCups Coffees")
+}
@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &ComputeTaxDecimalFunction{}
+
+type ComputeTaxDecimalFunction struct{}
+
+func NewComputeTaxDecimalFunction() function.Function {
+	return &ComputeTaxDecimalFunction{}
+}
+
+func (f *ComputeTaxDecimalFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "compute_tax_decimal"
+}
+
+func (f *ComputeTaxDecimalFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	modes := make([]string, 0, len(roundingModes))
+	for _, mode := range roundingModes {
+		modes = append(modes, string(mode))
+	}
+
+	resp.Definition = function.Definition{
+		Summary: "Compute tax for coffee with exact decimal arithmetic",
+		Description: "Given a price and tax rate, return the total cost including tax as a decimal string. " +
+			"Unlike compute_tax, the total is computed without floating point errors and rounded to the given currency precision using the given rounding mode.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "price",
+				Description: "Price of coffee item, such as \"1.005\". Numbers are converted to strings by Terraform without loss of precision.",
+				Validators: []function.StringParameterValidator{
+					nonNegativeValidator{},
+				},
+			},
+			function.StringParameter{
+				Name:        "rate",
+				Description: "Tax rate. \"0.085\" == 8.5%",
+				Validators: []function.StringParameterValidator{
+					taxRateValidator{},
+				},
+			},
+			function.StringParameter{
+				Name:        "rounding_mode",
+				Description: "How to round the total to the currency precision. One of: " + strings.Join(modes, ", ") + ".",
+			},
+			function.Int64Parameter{
+				Name:        "precision",
+				Description: fmt.Sprintf("Number of decimal places of the currency, from 0 to %d, such as 2 for USD or 0 for JPY.", maxPrecision),
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *ComputeTaxDecimalFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var price string
+	var rate string
+	var mode string
+	var precision int64
+
+	// Read Terraform argument data into the variables
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &price, &rate, &mode, &precision))
+	if resp.Error != nil {
+		return
+	}
+
+	priceDecimal, err := parseDecimal(price)
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0, err.Error()))
+	}
+
+	rateDecimal, err := parseDecimal(rate)
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(1, err.Error()))
+	}
+
+	if !slices.Contains(roundingModes, roundingMode(mode)) {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(2, fmt.Sprintf("unsupported rounding mode %q", mode)))
+	}
+
+	if precision < 0 || precision > maxPrecision {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(3, fmt.Sprintf("precision must be between 0 and %d", maxPrecision)))
+	}
+
+	if resp.Error != nil {
+		return
+	}
+
+	total := roundDecimal(addTax(priceDecimal, rateDecimal), int(precision), roundingMode(mode))
+
+	// Set the result
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, total.FloatString(int(precision))))
+}
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestComputeTaxDecimalFunction_Known(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+        output "half_up" {
+          value = provider::hashicups::compute_tax_decimal("1.005", "0", "half-up", 2)
+        }
+        output "half_even" {
+          value = provider::hashicups::compute_tax_decimal(1.005, 0, "half-even", 2)
+        }
+        output "floor" {
+          value = provider::hashicups::compute_tax_decimal("5.00", "0.085", "floor", 2)
+        }
+        output "yen" {
+          value = provider::hashicups::compute_tax_decimal("500", "0.085", "half-up", 0)
+        }
+        `,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("half_up", "1.01"),
+					resource.TestCheckOutput("half_even", "1.00"),
+					resource.TestCheckOutput("floor", "5.42"),
+					resource.TestCheckOutput("yen", "543"),
+				),
+			},
+		},
+	})
+}
+
+func TestComputeTaxDecimalFunction_Null(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+        output "test" {
+          value = provider::hashicups::compute_tax_decimal(null, "0.085", "half-up", 2)
+        }
+        `,
+				// The parameter does not enable AllowNullValue
+				ExpectError: regexp.MustCompile(`argument must not be null`),
+			},
+		},
+	})
+}
+
+func TestComputeTaxDecimalFunction_InvalidRoundingMode(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+        output "test" {
+          value = provider::hashicups::compute_tax_decimal("5.00", "0.085", "ceiling", 2)
+        }
+        `,
+				ExpectError: regexp.MustCompile(`unsupported rounding mode "ceiling"`),
+			},
+		},
+	})
+}
+
+func TestComputeTaxDecimalFunction_NegativePrice(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+        output "test" {
+          value = provider::hashicups::compute_tax_decimal("-5.00", "0.085", "half-even", 2)
+        }
+        `,
+				ExpectError: regexp.MustCompile(`value must not be negative`),
+			},
+		},
+	})
+}
+
+func TestComputeTaxDecimalFunction_PercentageRate(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+        output "test" {
+          value = provider::hashicups::compute_tax_decimal("5.00", "8.5", "half-even", 2)
+        }
+        `,
+				ExpectError: regexp.MustCompile(`tax rate must be a fraction between 0 and 1`),
+			},
+		},
+	})
+}
@@ -3,7 +3,6 @@ package provider
 import (
 	"context"
 	"github.com/hashicorp/terraform-plugin-framework/function"
-	"math"
 )
 
 // Ensure the implementation satisfies the desired interfaces.
@@ -28,10 +27,16 @@ func (f *ComputeTaxFunction) Definition(ctx context.Context, req function.Defini
 			function.Float64Parameter{
 				Name:        "price",
 				Description: "Price of coffee item.",
+				Validators: []function.Float64ParameterValidator{
+					nonNegativeValidator{},
+				},
 			},
 			function.Float64Parameter{
 				Name:        "rate",
 				Description: "Tax rate. 0.085 == 8.5%",
+				Validators: []function.Float64ParameterValidator{
+					taxRateValidator{},
+				},
 			},
 		},
 		Return: function.Float64Return{},
@@ -46,7 +51,7 @@ func (f *ComputeTaxFunction) Run(ctx context.Context, req function.RunRequest, r
 	// Read Terraform argument data into the variables
 	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &price, &rate))
 
-	total = math.Round((price+price*rate)*100) / 100
+	total = roundCents(addTax(decimalFromFloat64(price), decimalFromFloat64(rate)))
 
 	// Set the result
 	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, total))
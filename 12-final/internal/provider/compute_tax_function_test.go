@@ -48,3 +48,41 @@ func TestComputeTaxFunction_Null(t *testing.T) {
 		},
 	})
 }
+
+func TestComputeTaxFunction_NegativePrice(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+        output "test" {
+          value = provider::hashicups::compute_tax(-5.00, 0.085)
+        }
+        `,
+				ExpectError: regexp.MustCompile(`value must not be negative`),
+			},
+		},
+	})
+}
+
+func TestComputeTaxFunction_PercentageRate(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+        output "test" {
+          value = provider::hashicups::compute_tax(5.00, 8.5)
+        }
+        `,
+				ExpectError: regexp.MustCompile(`tax rate must be a fraction between 0 and 1`),
+			},
+		},
+	})
+}
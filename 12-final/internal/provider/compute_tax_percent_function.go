@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &ComputeTaxPercentFunction{}
+
+type ComputeTaxPercentFunction struct{}
+
+func NewComputeTaxPercentFunction() function.Function {
+	return &ComputeTaxPercentFunction{}
+}
+
+func (f *ComputeTaxPercentFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "compute_tax_percent"
+}
+
+func (f *ComputeTaxPercentFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Compute tax for coffee with a percentage tax rate",
+		Description: "Given a price and tax rate as a percentage, return the total cost including tax.",
+
+		Parameters: []function.Parameter{
+			function.Float64Parameter{
+				Name:        "price",
+				Description: "Price of coffee item.",
+				Validators: []function.Float64ParameterValidator{
+					nonNegativeValidator{},
+				},
+			},
+			function.Float64Parameter{
+				Name:        "percent",
+				Description: "Tax rate as a percentage. 8.5 == 8.5%",
+				Validators: []function.Float64ParameterValidator{
+					taxRateValidator{percent: true},
+				},
+			},
+		},
+		Return: function.Float64Return{},
+	}
+}
+
+func (f *ComputeTaxPercentFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var price float64
+	var percent float64
+	var total float64
+
+	// Read Terraform argument data into the variables
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &price, &percent))
+
+	rate := new(big.Rat).Quo(decimalFromFloat64(percent), big.NewRat(100, 1))
+	total = roundCents(addTax(decimalFromFloat64(price), rate))
+
+	// Set the result
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, total))
+}
@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// defaultCredentialsFile is the credentials file read when neither the
+	// config_file attribute nor HASHICUPS_CONFIG_FILE is set, relative to
+	// the home directory of the user.
+	defaultCredentialsFile = ".hashicups/credentials"
+
+	// defaultCredentialsProfile is the profile used when neither the
+	// profile attribute nor HASHICUPS_PROFILE is set.
+	defaultCredentialsProfile = "default"
+)
+
+// credentialsProfile holds the settings of a named profile in a HashiCups
+// credentials file. Settings missing from the profile are empty.
+type credentialsProfile struct {
+	Host     string
+	Username string
+	Password string
+}
+
+// readCredentialsFile reads the profiles of the credentials file at name.
+// A leading "~/" is expanded to the home directory of the user.
+func readCredentialsFile(name string) (map[string]credentialsProfile, error) {
+	name, err := expandHome(name)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	profiles, err := parseCredentials(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return profiles, nil
+}
+
+// parseCredentials parses a credentials file in the INI format used by
+// cloud CLIs, such as:
+//
+//	[default]
+//	host     = http://localhost:19090
+//	username = education
+//	password = test123
+//
+// Blank lines and lines starting with "#" or ";" are ignored.
+func parseCredentials(r io.Reader) (map[string]credentialsProfile, error) {
+	profiles := make(map[string]credentialsProfile)
+
+	var profile string
+
+	scanner := bufio.NewScanner(r)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: profile header must end with \"]\"", lineNumber)
+			}
+
+			profile = strings.TrimSpace(line[1 : len(line)-1])
+			if profile == "" {
+				return nil, fmt.Errorf("line %d: profile name must not be empty", lineNumber)
+			}
+
+			if _, ok := profiles[profile]; ok {
+				return nil, fmt.Errorf("line %d: duplicate profile %q", lineNumber, profile)
+			}
+
+			profiles[profile] = credentialsProfile{}
+
+			continue
+		}
+
+		if profile == "" {
+			return nil, fmt.Errorf("line %d: setting must follow a profile header, such as [default]", lineNumber)
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected a setting, such as \"host = http://localhost:19090\"", lineNumber)
+		}
+
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		settings := profiles[profile]
+
+		switch key {
+		case "host":
+			settings.Host = value
+		case "username":
+			settings.Username = value
+		case "password":
+			settings.Password = value
+		default:
+			return nil, fmt.Errorf("line %d: unsupported setting %q, expected host, username or password", lineNumber, key)
+		}
+
+		profiles[profile] = settings
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// expandHome replaces a leading "~/" in name with the home directory of
+// the user.
+func expandHome(name string) (string, error) {
+	rest, ok := strings.CutPrefix(name, "~/")
+	if !ok {
+		return name, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.New("cannot expand \"~\": " + err.Error())
+	}
+
+	return filepath.Join(home, rest), nil
+}
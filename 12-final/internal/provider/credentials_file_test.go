@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCredentials(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected map[string]credentialsProfile
+		err      string
+	}{
+		"empty": {
+			input:    "",
+			expected: map[string]credentialsProfile{},
+		},
+		"profiles": {
+			input: `
+# Local development
+[default]
+host     = http://localhost:19090
+username = education
+password = test123
+
+; Staging only overrides the host
+[ staging ]
+host=https://staging.example.com
+`,
+			expected: map[string]credentialsProfile{
+				"default": {
+					Host:     "http://localhost:19090",
+					Username: "education",
+					Password: "test123",
+				},
+				"staging": {
+					Host: "https://staging.example.com",
+				},
+			},
+		},
+		"password with equals sign": {
+			input: "[default]\npassword = a=b\n",
+			expected: map[string]credentialsProfile{
+				"default": {Password: "a=b"},
+			},
+		},
+		"setting before profile": {
+			input: "host = http://localhost:19090\n",
+			err:   "line 1: setting must follow a profile header",
+		},
+		"unterminated header": {
+			input: "[default\n",
+			err:   `line 1: profile header must end with "]"`,
+		},
+		"empty profile name": {
+			input: "[ ]\n",
+			err:   "line 1: profile name must not be empty",
+		},
+		"duplicate profile": {
+			input: "[default]\n[default]\n",
+			err:   `line 2: duplicate profile "default"`,
+		},
+		"missing equals sign": {
+			input: "[default]\nhost http://localhost:19090\n",
+			err:   "line 2: expected a setting",
+		},
+		"unsupported setting": {
+			input: "[default]\ntoken = abc\n",
+			err:   `line 2: unsupported setting "token"`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseCredentials(strings.NewReader(testCase.input))
+
+			if testCase.err != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.err) {
+					t.Fatalf("expected error containing %q, got: %v", testCase.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(got, testCase.expected) {
+				t.Errorf("expected %v, got %v", testCase.expected, got)
+			}
+		})
+	}
+}
+
+func TestReadCredentialsFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	name := filepath.Join(home, ".hashicups", "credentials")
+	writeCredentialsFile(t, name, "[default]\nusername = education\n\n[broken\n")
+
+	_, err := readCredentialsFile("~/.hashicups/credentials")
+	if err == nil || !strings.Contains(err.Error(), name+": line 4") {
+		t.Errorf("expected error naming the expanded file and line, got: %v", err)
+	}
+}
+
+// writeCredentialsFile writes a credentials file, creating its directory.
+func writeCredentialsFile(t *testing.T, name, contents string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o700); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := os.WriteFile(name, []byte(contents), 0o600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// clientErrorDiagnostic returns a diagnostic for an error returned by the
+// HashiCups client. Errors the provider can explain, such as rate limiting
+// or an authentication failure, get a specific diagnostic; any other error
+// gets the given summary and detail.
+func clientErrorDiagnostic(err error, summary, detail string) diag.Diagnostic {
+	var rateLimitedErr *rateLimitedError
+	if errors.As(err, &rateLimitedErr) {
+		return rateLimitedDiagnostic(err.Error())
+	}
+
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) {
+		return diag.NewErrorDiagnostic(summary, detail)
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return diag.NewErrorDiagnostic(
+			"HashiCups API Authentication Failed",
+			"The HashiCups API rejected the credentials of the provider. "+
+				"Check the username and password values in the configuration or the HASHICUPS_USERNAME and HASHICUPS_PASSWORD environment variables.\n\n"+
+				apiErr.details(),
+		)
+	case http.StatusNotFound:
+		return diag.NewErrorDiagnostic(
+			"HashiCups API Object Not Found",
+			"The HashiCups API could not find the requested object. "+
+				"It may have been deleted outside of Terraform.\n\n"+
+				apiErr.details(),
+		)
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return diag.NewErrorDiagnostic(
+			"HashiCups API Validation Failed",
+			"The HashiCups API rejected the request as invalid. "+
+				"Check that the configuration refers to existing coffees and uses positive quantities.\n\n"+
+				apiErr.details(),
+		)
+	case http.StatusTooManyRequests:
+		return rateLimitedDiagnostic(err.Error())
+	default:
+		return diag.NewErrorDiagnostic(summary, detail)
+	}
+}
+
+func rateLimitedDiagnostic(clientError string) diag.Diagnostic {
+	return diag.NewErrorDiagnostic(
+		"HashiCups API rate limited",
+		"The HashiCups API kept rejecting requests because too many were sent, and the rate limit did not reset within the operation timeout. "+
+			"Lower requests_per_second or max_concurrent_requests in the provider configuration, or try again later.\n\n"+
+			"HashiCups Client Error: "+clientError,
+	)
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestClientErrorDiagnostic(t *testing.T) {
+	testCases := map[string]struct {
+		err             error
+		expectedSummary string
+	}{
+		"unexpected": {
+			err:             errors.New("connection refused"),
+			expectedSummary: "Error creating order",
+		},
+		"unauthorized": {
+			err:             fmt.Errorf("wrapped: %w", &apiError{StatusCode: http.StatusUnauthorized}),
+			expectedSummary: "HashiCups API Authentication Failed",
+		},
+		"not-found": {
+			err:             &apiError{StatusCode: http.StatusNotFound},
+			expectedSummary: "HashiCups API Object Not Found",
+		},
+		"bad-request": {
+			err:             &apiError{StatusCode: http.StatusBadRequest},
+			expectedSummary: "HashiCups API Validation Failed",
+		},
+		"too-many-requests": {
+			err:             &apiError{StatusCode: http.StatusTooManyRequests},
+			expectedSummary: "HashiCups API rate limited",
+		},
+		"rate-limited": {
+			err:             &rateLimitedError{StatusCode: http.StatusTooManyRequests},
+			expectedSummary: "HashiCups API rate limited",
+		},
+		"internal-server-error": {
+			err:             &apiError{StatusCode: http.StatusInternalServerError},
+			expectedSummary: "Error creating order",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := clientErrorDiagnostic(testCase.err, "Error creating order", "Could not create order, unexpected error: "+testCase.err.Error())
+
+			if got.Summary() != testCase.expectedSummary {
+				t.Errorf("expected summary %q, got %q", testCase.expectedSummary, got.Summary())
+			}
+		})
+	}
+}
@@ -0,0 +1,417 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp-demoapp/hashicups-client-go"
+)
+
+const (
+	fakeAPIUsername = "education"
+	fakeAPIPassword = "test123"
+	// fakeAPIToken prefixes every token issued by the fake HashiCups API.
+	fakeAPIToken = "fake-token"
+)
+
+// fakeHashicupsAPI is an in-memory stand-in for the HashiCups API, so
+// resources can be tested without the docker-compose stack. It implements
+// the endpoints used by apiClient.
+type fakeHashicupsAPI struct {
+	server *httptest.Server
+
+	mu          sync.Mutex
+	coffees     []hashicups.Coffee
+	orders      map[int]hashicups.Order
+	nextOrderID int
+
+	// handlers replace the fake implementation of an endpoint, keyed by
+	// method and path such as "POST /orders".
+	handlers map[string]http.HandlerFunc
+
+	// faults are applied, in order, before requests are handled.
+	faults []*fakeAPIFault
+
+	// requests counts the requests received, keyed by method and path.
+	requests map[string]int
+
+	// tokens holds the tokens which have been issued and not expired.
+	tokens       map[string]bool
+	issuedTokens int
+}
+
+// fakeAPIFault scripts a failure of the fake HashiCups API, such as a slow
+// response, an error status or a dropped connection.
+type fakeAPIFault struct {
+	// Method and Path select the requests the fault applies to. Empty
+	// values match any request.
+	Method string
+	Path   string
+
+	// After is the number of matching requests to serve normally before
+	// the fault applies, such as to reject requests with a 401 status
+	// once a token has been used a number of times.
+	After int
+
+	// Times is the number of matching requests the fault applies to,
+	// after which requests are served normally. Zero applies the fault
+	// to every matching request.
+	Times int
+
+	// Latency delays the response. When it is the only field set, the
+	// request is then served normally.
+	Latency time.Duration
+
+	// StatusCode, Header and Body replace the response.
+	StatusCode int
+	Header     http.Header
+	Body       string
+
+	// DropConnection closes the connection without a response.
+	DropConnection bool
+
+	// MalformedJSON serves the normal response truncated halfway, so it
+	// is no longer valid JSON.
+	MalformedJSON bool
+
+	// matched counts the matching requests, including those served
+	// before After was reached.
+	matched int
+}
+
+// matches reports whether the fault applies to r, counting r as a matching
+// request. a.mu must be held.
+func (f *fakeAPIFault) matches(r *http.Request) bool {
+	if (f.Method != "" && f.Method != r.Method) || (f.Path != "" && f.Path != r.URL.Path) {
+		return false
+	}
+
+	f.matched++
+
+	if f.matched <= f.After {
+		return false
+	}
+
+	return f.Times == 0 || f.matched <= f.After+f.Times
+}
+
+// newFakeHashicupsAPI starts a fake HashiCups API which is stopped when the
+// test finishes. The test also gets its own session and transport
+// registries, as a later test could otherwise reuse a session cached for
+// an earlier fake API on the same port.
+func newFakeHashicupsAPI(t *testing.T) *fakeHashicupsAPI {
+	t.Helper()
+
+	isolateSessions(t)
+
+	api := &fakeHashicupsAPI{
+		coffees: []hashicups.Coffee{
+			{ID: 1, Name: "HCP Aeropress", Teaser: "Automation in a cup", Description: "", Price: 200, Image: "/hashicorp.png"},
+			{ID: 2, Name: "Packer Spiced Latte", Teaser: "Packed with goodness to spice up your images", Description: "", Price: 350, Image: "/packer.png"},
+			{ID: 3, Name: "Vaulatte", Teaser: "Nothing gives you a safe and secure feeling like a Vaulatte", Description: "", Price: 200, Image: "/vault.png"},
+		},
+		orders:      map[int]hashicups.Order{},
+		nextOrderID: 1,
+		handlers:    map[string]http.HandlerFunc{},
+		requests:    map[string]int{},
+		tokens:      map[string]bool{},
+	}
+
+	api.server = httptest.NewServer(http.HandlerFunc(api.serveHTTP))
+	t.Cleanup(api.server.Close)
+
+	return api
+}
+
+// URL returns the host to configure the provider with.
+func (a *fakeHashicupsAPI) URL() string {
+	return a.server.URL
+}
+
+// handle replaces the fake implementation of the endpoint with the given
+// method and path, such as "POST /orders".
+func (a *fakeHashicupsAPI) handle(pattern string, handler http.HandlerFunc) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.handlers[pattern] = handler
+}
+
+// inject adds a fault to the API. Faults apply in the order they were
+// injected, and the first fault matching a request which replaces the
+// response wins.
+func (a *fakeHashicupsAPI) inject(fault fakeAPIFault) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.faults = append(a.faults, &fault)
+}
+
+// clearFaults removes every injected fault, so requests are served
+// normally again.
+func (a *fakeHashicupsAPI) clearFaults() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.faults = nil
+}
+
+// requestCount returns the number of requests received with the given
+// method and path, such as "GET /orders/1", including failed requests.
+func (a *fakeHashicupsAPI) requestCount(pattern string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.requests[pattern]
+}
+
+// expireTokens expires every token issued so far, like the HashiCups API
+// does once a session has lasted too long.
+func (a *fakeHashicupsAPI) expireTokens() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	clear(a.tokens)
+}
+
+// order returns the stored order with the given ID.
+func (a *fakeHashicupsAPI) order(id int) (hashicups.Order, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	order, ok := a.orders[id]
+
+	return order, ok
+}
+
+// setOrder stores order, replacing any order with the same ID, as if it
+// had been changed outside of Terraform.
+func (a *fakeHashicupsAPI) setOrder(order hashicups.Order) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.orders[order.ID] = a.withCoffees(order)
+
+	if order.ID >= a.nextOrderID {
+		a.nextOrderID = order.ID + 1
+	}
+}
+
+// deleteOrder removes the order with the given ID, as if it had been
+// deleted outside of Terraform.
+func (a *fakeHashicupsAPI) deleteOrder(id int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.orders, id)
+}
+
+func (a *fakeHashicupsAPI) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	a.requests[r.Method+" "+r.URL.Path]++
+	handler := a.handlers[r.Method+" "+r.URL.Path]
+
+	var latency time.Duration
+	var fault *fakeAPIFault
+
+	for _, f := range a.faults {
+		if !f.matches(r) {
+			continue
+		}
+
+		latency += f.Latency
+
+		if fault == nil && (f.StatusCode != 0 || f.DropConnection || f.MalformedJSON) {
+			fault = f
+		}
+	}
+	a.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if fault != nil {
+		switch {
+		case fault.DropConnection:
+			dropConnection(w)
+			return
+		case fault.MalformedJSON:
+			recorder := httptest.NewRecorder()
+			a.serve(recorder, r, handler)
+
+			body := recorder.Body.Bytes()
+			w.WriteHeader(recorder.Code)
+			_, _ = w.Write(body[:len(body)/2])
+			return
+		default:
+			for name, values := range fault.Header {
+				w.Header()[name] = values
+			}
+			w.WriteHeader(fault.StatusCode)
+			_, _ = w.Write([]byte(fault.Body))
+			return
+		}
+	}
+
+	a.serve(w, r, handler)
+}
+
+// serve handles r with handler, if set, or the fake implementation.
+func (a *fakeHashicupsAPI) serve(w http.ResponseWriter, r *http.Request, handler http.HandlerFunc) {
+	if handler != nil {
+		handler(w, r)
+		return
+	}
+
+	if r.Method == http.MethodPost && r.URL.Path == "/signin" {
+		a.signIn(w, r)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.tokens[r.Header.Get("Authorization")] {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/coffees" && r.Method == http.MethodGet:
+		writeJSON(w, a.coffees)
+	case r.URL.Path == "/orders" && r.Method == http.MethodGet:
+		orders := []hashicups.Order{}
+		for id := 1; id < a.nextOrderID; id++ {
+			if order, ok := a.orders[id]; ok {
+				orders = append(orders, order)
+			}
+		}
+		writeJSON(w, orders)
+	case r.URL.Path == "/orders" && r.Method == http.MethodPost:
+		var items []hashicups.OrderItem
+		if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		order := a.withCoffees(hashicups.Order{ID: a.nextOrderID, Items: items})
+		a.orders[order.ID] = order
+		a.nextOrderID++
+
+		writeJSON(w, order)
+	case strings.HasPrefix(r.URL.Path, "/orders/"):
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/orders/"))
+		if err != nil {
+			http.Error(w, "Invalid order ID", http.StatusBadRequest)
+			return
+		}
+
+		order, ok := a.orders[id]
+		if !ok {
+			http.Error(w, "Order not found", http.StatusNotFound)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, order)
+		case http.MethodPut:
+			var items []hashicups.OrderItem
+			if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			order = a.withCoffees(hashicups.Order{ID: id, Items: items})
+			a.orders[id] = order
+
+			// Like the HashiCups API, the items of the updated order are
+			// not populated.
+			writeJSON(w, hashicups.Order{ID: id})
+		case http.MethodDelete:
+			delete(a.orders, id)
+			_, _ = w.Write([]byte("Deleted order"))
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (a *fakeHashicupsAPI) signIn(w http.ResponseWriter, r *http.Request) {
+	var auth hashicups.AuthStruct
+	if err := json.NewDecoder(r.Body).Decode(&auth); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if auth.Username != fakeAPIUsername || auth.Password != fakeAPIPassword {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	a.mu.Lock()
+	a.issuedTokens++
+	token := fmt.Sprintf("%s-%d", fakeAPIToken, a.issuedTokens)
+	a.tokens[token] = true
+	a.mu.Unlock()
+
+	writeJSON(w, hashicups.AuthResponse{
+		UserID:   1,
+		Username: auth.Username,
+		Token:    token,
+	})
+}
+
+// withCoffees populates the coffee of each order item from the catalog, as
+// the HashiCups API does. a.mu must be held.
+func (a *fakeHashicupsAPI) withCoffees(order hashicups.Order) hashicups.Order {
+	items := make([]hashicups.OrderItem, 0, len(order.Items))
+
+	for _, item := range order.Items {
+		for _, coffee := range a.coffees {
+			if coffee.ID == item.Coffee.ID {
+				item.Coffee = coffee
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	order.Items = items
+
+	return order
+}
+
+// dropConnection closes the connection of w without writing a response.
+func dropConnection(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic("fake HashiCups API: response writer does not support hijacking")
+	}
+
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic("fake HashiCups API: " + err.Error())
+	}
+
+	_ = conn.Close()
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &FitBudgetFunction{}
+
+type FitBudgetFunction struct{}
+
+// fitBudgetCoffeeModel maps the attributes of a hashicups_coffees coffee
+// which are needed to fit an order within a budget. Terraform discards any
+// other attributes when converting the argument.
+type fitBudgetCoffeeModel struct {
+	ID    types.Int64   `tfsdk:"id"`
+	Price types.Float64 `tfsdk:"price"`
+}
+
+// fitBudgetItemModel maps an element of the fit_budget result.
+type fitBudgetItemModel struct {
+	CoffeeID types.Int64 `tfsdk:"coffee_id"`
+	Quantity types.Int64 `tfsdk:"quantity"`
+}
+
+func NewFitBudgetFunction() function.Function {
+	return &FitBudgetFunction{}
+}
+
+func (f *FitBudgetFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "fit_budget"
+}
+
+func (f *FitBudgetFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Fit an order within a budget",
+		Description: "Given a list of coffees, such as the coffees attribute of the hashicups_coffees data source, a budget and a tax rate, " +
+			"return the order items with the most coffees whose total cost including tax, as computed by compute_tax, is within the budget. " +
+			"The cheapest coffee maximizes the number of items, so the result has at most one item; when several coffees share the lowest price, the one with the lowest ID is chosen. " +
+			"Returns an empty list when the budget does not cover a single coffee.",
+
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:        "coffees",
+				Description: "Coffees to choose from. Each element must have the id and price attributes.",
+				ElementType: types.ObjectType{
+					AttrTypes: map[string]attr.Type{
+						"id":    types.Int64Type,
+						"price": types.Float64Type,
+					},
+				},
+			},
+			function.Float64Parameter{
+				Name:        "budget",
+				Description: "Maximum total cost of the order, including tax.",
+				Validators: []function.Float64ParameterValidator{
+					nonNegativeValidator{},
+				},
+			},
+			function.Float64Parameter{
+				Name:        "tax_rate",
+				Description: "Tax rate. 0.085 == 8.5%",
+				Validators: []function.Float64ParameterValidator{
+					taxRateValidator{},
+				},
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.ObjectType{
+				AttrTypes: map[string]attr.Type{
+					"coffee_id": types.Int64Type,
+					"quantity":  types.Int64Type,
+				},
+			},
+		},
+	}
+}
+
+func (f *FitBudgetFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var coffees []fitBudgetCoffeeModel
+	var budget float64
+	var rate float64
+
+	// Read Terraform argument data into the variables
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &coffees, &budget, &rate))
+	if resp.Error != nil {
+		return
+	}
+
+	var cheapest *fitBudgetCoffeeModel
+	var cheapestPrice *big.Rat
+
+	for index := range coffees {
+		coffee := &coffees[index]
+
+		if coffee.ID.IsNull() {
+			resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0, fmt.Sprintf("coffees[%d].id must not be null", index)))
+			continue
+		}
+
+		// A free coffee would make the number of items unbounded.
+		if coffee.Price.IsNull() || coffee.Price.ValueFloat64() <= 0 {
+			resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0, fmt.Sprintf("coffees[%d].price must be greater than zero", index)))
+			continue
+		}
+
+		price := decimalFromFloat64(coffee.Price.ValueFloat64())
+
+		if cheapest == nil || price.Cmp(cheapestPrice) < 0 ||
+			(price.Cmp(cheapestPrice) == 0 && coffee.ID.ValueInt64() < cheapest.ID.ValueInt64()) {
+			cheapest = coffee
+			cheapestPrice = price
+		}
+	}
+
+	if resp.Error != nil {
+		return
+	}
+
+	items := []fitBudgetItemModel{}
+
+	if cheapest != nil {
+		quantity, err := maxQuantityWithinBudget(cheapestPrice, decimalFromFloat64(budget), decimalFromFloat64(rate))
+		if err != nil {
+			resp.Error = function.NewArgumentFuncError(1, err.Error())
+			return
+		}
+
+		if quantity > 0 {
+			items = append(items, fitBudgetItemModel{
+				CoffeeID: cheapest.ID,
+				Quantity: types.Int64Value(quantity),
+			})
+		}
+	}
+
+	// Set the result
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, items))
+}
+
+// maxQuantityWithinBudget returns the largest quantity of a coffee at price
+// whose total including tax at rate, rounded to cents, is within budget.
+// price must be greater than zero.
+func maxQuantityWithinBudget(price, budget, rate *big.Rat) (int64, error) {
+	fits := func(quantity int64) bool {
+		subtotal := new(big.Rat).Mul(price, new(big.Rat).SetInt64(quantity))
+		total := roundDecimal(addTax(subtotal, rate), 2, roundingModeHalfUp)
+
+		return total.Cmp(budget) <= 0
+	}
+
+	// Start from the unrounded estimate, then correct it for rounding of
+	// the total to cents, which may allow one more item or one fewer.
+	estimate := new(big.Rat).Quo(budget, addTax(price, rate))
+	estimateQuantity := new(big.Int).Quo(estimate.Num(), estimate.Denom())
+
+	// Leave room for the correction below.
+	if !estimateQuantity.IsInt64() || estimateQuantity.Int64() == math.MaxInt64 {
+		return 0, fmt.Errorf("budget allows more than %d items", int64(math.MaxInt64))
+	}
+
+	quantity := estimateQuantity.Int64()
+
+	for fits(quantity + 1) {
+		quantity++
+	}
+
+	for quantity > 0 && !fits(quantity) {
+		quantity--
+	}
+
+	return quantity, nil
+}
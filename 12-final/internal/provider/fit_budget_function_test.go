@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"math/big"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestFitBudgetFunction_Known(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+        locals {
+          coffees = [
+            { id = 1, name = "HCP Aeropress", price = 2.00 },
+            { id = 3, name = "Nomadicano", price = 1.00 },
+            { id = 2, name = "Vaulatte", price = 1.00 },
+          ]
+        }
+
+        output "coffee_id" {
+          value = provider::hashicups::fit_budget(local.coffees, 10, 0.085)[0].coffee_id
+        }
+        output "quantity" {
+          value = provider::hashicups::fit_budget(local.coffees, 10, 0.085)[0].quantity
+        }
+        output "too_small" {
+          value = length(provider::hashicups::fit_budget(local.coffees, 1, 0.085))
+        }
+        `,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("coffee_id", "2"),
+					resource.TestCheckOutput("quantity", "9"),
+					resource.TestCheckOutput("too_small", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestFitBudgetFunction_FreeCoffee(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+        output "test" {
+          value = provider::hashicups::fit_budget([{ id = 1, price = 0 }], 10, 0.085)
+        }
+        `,
+				ExpectError: regexp.MustCompile(`coffees\[0\].price must be greater than zero`),
+			},
+		},
+	})
+}
+
+func TestFitBudgetFunction_NegativeBudget(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+        output "test" {
+          value = provider::hashicups::fit_budget([{ id = 1, price = 2 }], -10, 0.085)
+        }
+        `,
+				ExpectError: regexp.MustCompile(`value must not be negative`),
+			},
+		},
+	})
+}
+
+func TestMaxQuantityWithinBudget(t *testing.T) {
+	testCases := map[string]struct {
+		price    string
+		budget   string
+		rate     string
+		expected int64
+	}{
+		"no-tax": {
+			price:    "2.5",
+			budget:   "10",
+			rate:     "0",
+			expected: 4,
+		},
+		"tax": {
+			price:    "1",
+			budget:   "10",
+			rate:     "0.085",
+			expected: 9,
+		},
+		"rounded-total-exceeds-budget": {
+			// 9 * 1.085 = 9.765, which rounds to 9.77.
+			price:    "1",
+			budget:   "9.76",
+			rate:     "0.085",
+			expected: 8,
+		},
+		"rounded-total-within-budget": {
+			// 2 * 1.08502 = 2.17004, which rounds to 2.17.
+			price:    "1",
+			budget:   "2.17",
+			rate:     "0.08502",
+			expected: 2,
+		},
+		"budget-too-small": {
+			price:    "2",
+			budget:   "1.99",
+			rate:     "0",
+			expected: 0,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := maxQuantityWithinBudget(mustParseDecimal(t, testCase.price), mustParseDecimal(t, testCase.budget), mustParseDecimal(t, testCase.rate))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != testCase.expected {
+				t.Errorf("expected %d, got %d", testCase.expected, got)
+			}
+		})
+	}
+}
+
+func TestMaxQuantityWithinBudget_TooManyItems(t *testing.T) {
+	_, err := maxQuantityWithinBudget(mustParseDecimal(t, "0.01"), mustParseDecimal(t, "1e300"), mustParseDecimal(t, "0"))
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+}
+
+func mustParseDecimal(t *testing.T, s string) *big.Rat {
+	t.Helper()
+
+	r, err := parseDecimal(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return r
+}
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementations satisfy the desired interfaces.
+var (
+	_ function.Float64ParameterValidator = nonNegativeValidator{}
+	_ function.Int64ParameterValidator   = nonNegativeValidator{}
+	_ function.StringParameterValidator  = nonNegativeValidator{}
+	_ function.Float64ParameterValidator = taxRateValidator{}
+	_ function.StringParameterValidator  = taxRateValidator{}
+)
+
+// nonNegativeValidator rejects negative amounts, such as prices and
+// quantities. String parameters are validated as decimal strings, such as
+// "1.005".
+type nonNegativeValidator struct{}
+
+func (v nonNegativeValidator) ValidateParameterFloat64(ctx context.Context, req function.Float64ParameterValidatorRequest, resp *function.Float64ParameterValidatorResponse) {
+	if req.Value.IsNull() || req.Value.IsUnknown() {
+		return
+	}
+
+	resp.Error = v.validate(req.ArgumentPosition, decimalFromFloat64(req.Value.ValueFloat64()), fmt.Sprint(req.Value.ValueFloat64()))
+}
+
+func (v nonNegativeValidator) ValidateParameterInt64(ctx context.Context, req function.Int64ParameterValidatorRequest, resp *function.Int64ParameterValidatorResponse) {
+	if req.Value.IsNull() || req.Value.IsUnknown() {
+		return
+	}
+
+	resp.Error = v.validate(req.ArgumentPosition, new(big.Rat).SetInt64(req.Value.ValueInt64()), fmt.Sprint(req.Value.ValueInt64()))
+}
+
+func (v nonNegativeValidator) ValidateParameterString(ctx context.Context, req function.StringParameterValidatorRequest, resp *function.StringParameterValidatorResponse) {
+	if req.Value.IsNull() || req.Value.IsUnknown() {
+		return
+	}
+
+	// Values which are not decimal numbers are reported by the function.
+	amount, err := parseDecimal(req.Value.ValueString())
+	if err != nil {
+		return
+	}
+
+	resp.Error = v.validate(req.ArgumentPosition, amount, req.Value.ValueString())
+}
+
+// validate returns an error for the argument at position if amount, shown
+// as value, is negative.
+func (v nonNegativeValidator) validate(position int64, amount *big.Rat, value string) *function.FuncError {
+	if amount.Sign() < 0 {
+		return function.NewArgumentFuncError(position, "value must not be negative, got: "+value)
+	}
+
+	return nil
+}
+
+// taxRateValidator rejects negative tax rates and rates above 100%, which
+// usually mean a percentage was passed where a fraction was expected.
+// String parameters are validated as decimal strings, such as "0.085".
+type taxRateValidator struct {
+	// percent is set when the rate is a percentage, such as 8.5 for 8.5%,
+	// rather than a fraction, such as 0.085.
+	percent bool
+}
+
+func (v taxRateValidator) ValidateParameterFloat64(ctx context.Context, req function.Float64ParameterValidatorRequest, resp *function.Float64ParameterValidatorResponse) {
+	if req.Value.IsNull() || req.Value.IsUnknown() {
+		return
+	}
+
+	resp.Error = v.validate(req.ArgumentPosition, decimalFromFloat64(req.Value.ValueFloat64()), fmt.Sprint(req.Value.ValueFloat64()))
+}
+
+func (v taxRateValidator) ValidateParameterString(ctx context.Context, req function.StringParameterValidatorRequest, resp *function.StringParameterValidatorResponse) {
+	if req.Value.IsNull() || req.Value.IsUnknown() {
+		return
+	}
+
+	// Values which are not decimal numbers are reported by the function.
+	rate, err := parseDecimal(req.Value.ValueString())
+	if err != nil {
+		return
+	}
+
+	resp.Error = v.validate(req.ArgumentPosition, rate, req.Value.ValueString())
+}
+
+// validate returns an error for the argument at position if rate, shown as
+// value, is out of range.
+func (v taxRateValidator) validate(position int64, rate *big.Rat, value string) *function.FuncError {
+	if rate.Sign() < 0 {
+		return function.NewArgumentFuncError(position, "tax rate must not be negative, got: "+value)
+	}
+
+	if v.percent && rate.Cmp(big.NewRat(100, 1)) > 0 {
+		return function.NewArgumentFuncError(position, "tax rate must be a percentage between 0 and 100, got: "+value)
+	}
+
+	if !v.percent && rate.Cmp(big.NewRat(1, 1)) > 0 {
+		return function.NewArgumentFuncError(position, "tax rate must be a fraction between 0 and 1, such as 0.085 for 8.5%, got: "+value+". "+
+			"Use compute_tax_percent to pass the rate as a percentage.")
+	}
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestTaxRateValidator(t *testing.T) {
+	testCases := map[string]struct {
+		validator   taxRateValidator
+		value       types.Float64
+		expectError bool
+	}{
+		"fraction": {
+			value: types.Float64Value(0.085),
+		},
+		"fraction-negative": {
+			value:       types.Float64Value(-0.085),
+			expectError: true,
+		},
+		"fraction-percentage": {
+			value:       types.Float64Value(8.5),
+			expectError: true,
+		},
+		"percent": {
+			validator: taxRateValidator{percent: true},
+			value:     types.Float64Value(8.5),
+		},
+		"percent-too-large": {
+			validator:   taxRateValidator{percent: true},
+			value:       types.Float64Value(850),
+			expectError: true,
+		},
+		"null": {
+			value: types.Float64Null(),
+		},
+		"unknown": {
+			value: types.Float64Unknown(),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := function.Float64ParameterValidatorRequest{
+				ArgumentPosition: 1,
+				Value:            testCase.value,
+			}
+			resp := &function.Float64ParameterValidatorResponse{}
+
+			testCase.validator.ValidateParameterFloat64(context.Background(), req, resp)
+
+			if testCase.expectError != (resp.Error != nil) {
+				t.Fatalf("expected error %t, got: %v", testCase.expectError, resp.Error)
+			}
+
+			if resp.Error != nil && (resp.Error.FunctionArgument == nil || *resp.Error.FunctionArgument != 1) {
+				t.Errorf("expected error for argument 1, got: %v", resp.Error.FunctionArgument)
+			}
+		})
+	}
+}
+
+func TestTaxRateValidator_String(t *testing.T) {
+	testCases := map[string]struct {
+		validator   taxRateValidator
+		value       types.String
+		expectError bool
+	}{
+		"fraction": {
+			value: types.StringValue("0.085"),
+		},
+		"fraction-negative": {
+			value:       types.StringValue("-0.085"),
+			expectError: true,
+		},
+		"fraction-percentage": {
+			value:       types.StringValue("8.5"),
+			expectError: true,
+		},
+		"percent": {
+			validator: taxRateValidator{percent: true},
+			value:     types.StringValue("8.5"),
+		},
+		"invalid": {
+			value: types.StringValue("eight"),
+		},
+		"null": {
+			value: types.StringNull(),
+		},
+		"unknown": {
+			value: types.StringUnknown(),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := function.StringParameterValidatorRequest{
+				ArgumentPosition: 1,
+				Value:            testCase.value,
+			}
+			resp := &function.StringParameterValidatorResponse{}
+
+			testCase.validator.ValidateParameterString(context.Background(), req, resp)
+
+			if testCase.expectError != (resp.Error != nil) {
+				t.Fatalf("expected error %t, got: %v", testCase.expectError, resp.Error)
+			}
+
+			if resp.Error != nil && (resp.Error.FunctionArgument == nil || *resp.Error.FunctionArgument != 1) {
+				t.Errorf("expected error for argument 1, got: %v", resp.Error.FunctionArgument)
+			}
+		})
+	}
+}
+
+func TestNonNegativeValidator_String(t *testing.T) {
+	testCases := map[string]struct {
+		value       types.String
+		expectError bool
+	}{
+		"positive": {
+			value: types.StringValue("1.005"),
+		},
+		"zero": {
+			value: types.StringValue("0"),
+		},
+		"negative": {
+			value:       types.StringValue("-0.001"),
+			expectError: true,
+		},
+		"invalid": {
+			value: types.StringValue("free"),
+		},
+		"null": {
+			value: types.StringNull(),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := function.StringParameterValidatorRequest{
+				ArgumentPosition: 0,
+				Value:            testCase.value,
+			}
+			resp := &function.StringParameterValidatorResponse{}
+
+			nonNegativeValidator{}.ValidateParameterString(context.Background(), req, resp)
+
+			if testCase.expectError != (resp.Error != nil) {
+				t.Fatalf("expected error %t, got: %v", testCase.expectError, resp.Error)
+			}
+		})
+	}
+}
+
+func TestNonNegativeValidator_Int64(t *testing.T) {
+	testCases := map[string]struct {
+		value       types.Int64
+		expectError bool
+	}{
+		"positive": {
+			value: types.Int64Value(2),
+		},
+		"zero": {
+			value: types.Int64Value(0),
+		},
+		"negative": {
+			value:       types.Int64Value(-1),
+			expectError: true,
+		},
+		"null": {
+			value: types.Int64Null(),
+		},
+		"unknown": {
+			value: types.Int64Unknown(),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := function.Int64ParameterValidatorRequest{
+				ArgumentPosition: 1,
+				Value:            testCase.value,
+			}
+			resp := &function.Int64ParameterValidatorResponse{}
+
+			nonNegativeValidator{}.ValidateParameterInt64(context.Background(), req, resp)
+
+			if testCase.expectError != (resp.Error != nil) {
+				t.Fatalf("expected error %t, got: %v", testCase.expectError, resp.Error)
+			}
+
+			if resp.Error != nil && (resp.Error.FunctionArgument == nil || *resp.Error.FunctionArgument != 1) {
+				t.Errorf("expected error for argument 1, got: %v", resp.Error.FunctionArgument)
+			}
+		})
+	}
+}
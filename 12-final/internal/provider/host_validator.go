@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ validator.String = hostValidator{}
+
+// hostValidator rejects host values which are not absolute HTTP URLs, such
+// as "localhost:19090", which the HashiCups client would otherwise fail on
+// with a confusing error.
+type hostValidator struct{}
+
+func (v hostValidator) Description(_ context.Context) string {
+	return "value must be an absolute http or https URL without a query, such as http://localhost:19090"
+}
+
+func (v hostValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v hostValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	// Empty values are reported by Configure, along with the other sources
+	// of the host.
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() || req.ConfigValue.ValueString() == "" {
+		return
+	}
+
+	if err := validateHost(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid HashiCups API Host",
+			fmt.Sprintf("The host %q is not valid: %s.", req.ConfigValue.ValueString(), err),
+		)
+	}
+}
+
+// validateHost returns an error explaining why host is not a valid
+// HashiCups API URL, if it is not.
+func validateHost(host string) error {
+	u, err := url.Parse(host)
+	if err != nil {
+		return errors.New("it cannot be parsed as a URL")
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("it must be an absolute URL with an http or https scheme, such as http://localhost:19090")
+	}
+
+	if u.User != nil {
+		return errors.New("it must not contain credentials, set the username and password values instead")
+	}
+
+	if u.RawQuery != "" || u.ForceQuery {
+		return errors.New("it must not contain a query")
+	}
+
+	if u.Fragment != "" {
+		return errors.New("it must not contain a fragment")
+	}
+
+	return nil
+}
+
+// normalizeHost removes trailing slashes from host, as the HashiCups
+// client appends paths such as "/orders" to it.
+func normalizeHost(host string) string {
+	return strings.TrimRight(host, "/")
+}
@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &ImageURLFunction{}
+
+type ImageURLFunction struct{}
+
+func NewImageURLFunction() function.Function {
+	return &ImageURLFunction{}
+}
+
+func (f *ImageURLFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "image_url"
+}
+
+func (f *ImageURLFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Resolve a coffee image URL",
+		Description: "Given the HashiCups API host and the image of a coffee, such as \"/hashicorp.png\", return the absolute URL of the image. " +
+			"Images which are already absolute URLs are returned unchanged.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "host",
+				Description: "URI for HashiCups API, such as \"http://localhost:19090\".",
+			},
+			function.StringParameter{
+				Name:        "image",
+				Description: "URI for an image of the coffee, as returned by the HashiCups API.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *ImageURLFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var host string
+	var image string
+
+	// Read Terraform argument data into the variables
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &host, &image))
+	if resp.Error != nil {
+		return
+	}
+
+	hostURL, err := url.Parse(host)
+	if err != nil || hostURL.Scheme == "" || hostURL.Host == "" {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0, fmt.Sprintf("host must be an absolute URL, such as \"http://localhost:19090\", got: %q", host)))
+	}
+
+	imageURL, err := url.Parse(image)
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(1, fmt.Sprintf("image must be a URL or path, got: %q", image)))
+	}
+
+	if resp.Error != nil {
+		return
+	}
+
+	// Set the result
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, hostURL.ResolveReference(imageURL).String()))
+}
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestImageURLFunction_Known(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+        output "relative" {
+          value = provider::hashicups::image_url("http://localhost:19090/", "/hashicorp.png")
+        }
+        output "absolute" {
+          value = provider::hashicups::image_url("http://localhost:19090", "https://example.com/packer.png")
+        }
+        `,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("relative", "http://localhost:19090/hashicorp.png"),
+					resource.TestCheckOutput("absolute", "https://example.com/packer.png"),
+				),
+			},
+		},
+	})
+}
+
+func TestImageURLFunction_InvalidHost(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+        output "test" {
+          value = provider::hashicups::image_url("localhost:19090", "/hashicorp.png")
+        }
+        `,
+				ExpectError: regexp.MustCompile(`host must be an absolute URL`),
+			},
+		},
+	})
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &LineTotalFunction{}
+
+type LineTotalFunction struct{}
+
+func NewLineTotalFunction() function.Function {
+	return &LineTotalFunction{}
+}
+
+func (f *LineTotalFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "line_total"
+}
+
+func (f *LineTotalFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Compute the cost of an order item",
+		Description: "Given a price and quantity, return the cost of the order item before tax.",
+
+		Parameters: []function.Parameter{
+			function.Float64Parameter{
+				Name:        "price",
+				Description: "Price of coffee item.",
+				Validators: []function.Float64ParameterValidator{
+					nonNegativeValidator{},
+				},
+			},
+			function.Int64Parameter{
+				Name:        "quantity",
+				Description: "Count of this item in the order.",
+				Validators: []function.Int64ParameterValidator{
+					nonNegativeValidator{},
+				},
+			},
+		},
+		Return: function.Float64Return{},
+	}
+}
+
+func (f *LineTotalFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var price float64
+	var quantity int64
+
+	// Read Terraform argument data into the variables
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &price, &quantity))
+	if resp.Error != nil {
+		return
+	}
+
+	total := roundCents(new(big.Rat).Mul(decimalFromFloat64(price), new(big.Rat).SetInt64(quantity)))
+
+	// Set the result
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, total))
+}
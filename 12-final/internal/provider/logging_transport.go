@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// apiLogSubsystem is the tflog subsystem for HashiCups API requests. Its
+// level follows TF_LOG_PROVIDER_HASHICUPS, unless overridden with
+// TF_LOG_PROVIDER_HASHICUPS_API.
+const apiLogSubsystem = "api"
+
+// withAPILogSubsystem returns ctx with the api subsystem logger. The
+// subsystem includes the fields of the root logger, such as the order ID
+// set by the resource making the request.
+func withAPILogSubsystem(ctx context.Context) context.Context {
+	ctx = tflog.NewSubsystem(ctx, apiLogSubsystem,
+		tflog.WithLevelFromEnv("TF_LOG_PROVIDER_HASHICUPS", apiLogSubsystem),
+		tflog.WithRootFields(),
+	)
+
+	return withSubsystemLogRedaction(ctx, apiLogSubsystem)
+}
+
+// loggingTransport logs each HashiCups API request and its outcome to the
+// api subsystem. The duration includes any throttling and retries. Headers
+// are only logged at trace level, with secrets redacted.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	tflog.SubsystemDebug(ctx, apiLogSubsystem, "Sending HashiCups API request", map[string]any{
+		"http_method": req.Method,
+		"http_path":   req.URL.Path,
+	})
+
+	tflog.SubsystemTrace(ctx, apiLogSubsystem, "HashiCups API request headers", map[string]any{
+		"http_method":          req.Method,
+		"http_path":            req.URL.Path,
+		"http_request_headers": redactHeaders(req.Header),
+	})
+
+	start := time.Now()
+
+	resp, err := t.next.RoundTrip(req)
+
+	fields := map[string]any{
+		"http_method": req.Method,
+		"http_path":   req.URL.Path,
+		"duration":    time.Since(start).String(),
+	}
+
+	if err != nil {
+		fields["error"] = err.Error()
+
+		tflog.SubsystemWarn(ctx, apiLogSubsystem, "HashiCups API request failed", fields)
+
+		return nil, err
+	}
+
+	fields["http_status"] = resp.StatusCode
+
+	if requestID := resp.Header.Get("X-Request-Id"); requestID != "" {
+		fields["http_request_id"] = requestID
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		tflog.SubsystemWarn(ctx, apiLogSubsystem, "HashiCups API request failed", fields)
+	} else {
+		tflog.SubsystemDebug(ctx, apiLogSubsystem, "Received HashiCups API response", fields)
+	}
+
+	tflog.SubsystemTrace(ctx, apiLogSubsystem, "HashiCups API response headers", map[string]any{
+		"http_method":           req.Method,
+		"http_path":             req.URL.Path,
+		"http_response_headers": redactHeaders(resp.Header),
+	})
+
+	return resp, nil
+}
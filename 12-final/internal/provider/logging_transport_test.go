@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp-demoapp/hashicups-client-go"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestLoggingTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-123")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	ctx = tflog.SetField(ctx, "order_id", "7")
+	ctx = tflog.SetField(ctx, "hashicups_password", "test123")
+
+	client := &hashicups.Client{
+		HostURL:    server.URL,
+		HTTPClient: newHTTPClient(httpClientConfig{}),
+	}
+
+	_, err := clientWithContext(ctx, client).GetCoffees()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var entry map[string]any
+
+	for _, e := range entries {
+		if e["@message"] == "Received HashiCups API response" {
+			entry = e
+		}
+	}
+
+	if entry == nil {
+		t.Fatalf("expected response to be logged, got: %v", entries)
+	}
+
+	expected := map[string]any{
+		"@message":           "Received HashiCups API response",
+		"@module":            "provider.api",
+		"http_method":        "GET",
+		"http_path":          "/coffees",
+		"http_status":        float64(http.StatusOK),
+		"http_request_id":    "req-123",
+		"order_id":           "7",
+		"hashicups_password": "***",
+	}
+
+	for key, value := range expected {
+		if entry[key] != value {
+			t.Errorf("expected %s %v, got %v", key, value, entry[key])
+		}
+	}
+
+	if _, ok := entry["duration"]; !ok {
+		t.Error("expected duration field")
+	}
+}
+
+func TestLoggingTransport_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Order not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	client := &hashicups.Client{
+		HostURL:    server.URL,
+		HTTPClient: newHTTPClient(httpClientConfig{}),
+	}
+
+	_, err := clientWithContext(ctx, client).GetOrder("7")
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var entry map[string]any
+
+	for _, e := range entries {
+		if e["@message"] == "HashiCups API request failed" {
+			entry = e
+		}
+	}
+
+	expected := map[string]any{
+		"@level":      "warn",
+		"@message":    "HashiCups API request failed",
+		"http_path":   "/orders/7",
+		"http_status": float64(http.StatusNotFound),
+	}
+
+	for key, value := range expected {
+		if entry[key] != value {
+			t.Errorf("expected %s %v, got %v", key, value, entry[key])
+		}
+	}
+}
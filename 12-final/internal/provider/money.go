@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+)
+
+// roundingMode controls how amounts are rounded to the currency precision.
+type roundingMode string
+
+const (
+	// roundingModeHalfUp rounds halves away from zero, so 1.005 becomes
+	// 1.01 and -1.005 becomes -1.01.
+	roundingModeHalfUp roundingMode = "half-up"
+
+	// roundingModeHalfEven rounds halves to the nearest even digit, also
+	// known as banker's rounding, so 1.005 becomes 1.00 and 1.015 becomes
+	// 1.02.
+	roundingModeHalfEven roundingMode = "half-even"
+
+	// roundingModeFloor rounds towards negative infinity.
+	roundingModeFloor roundingMode = "floor"
+)
+
+// roundingModes lists the supported rounding modes in documentation order.
+var roundingModes = []roundingMode{
+	roundingModeHalfUp,
+	roundingModeHalfEven,
+	roundingModeFloor,
+}
+
+// maxPrecision is the largest number of decimal places supported.
+const maxPrecision = 18
+
+// decimalPattern matches plain decimal numbers, optionally with an
+// exponent. big.Rat.SetString also accepts fractions such as "1/3", which
+// are not valid amounts.
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+
+// parseDecimal parses a decimal string, such as "1.005", exactly.
+func parseDecimal(s string) (*big.Rat, error) {
+	if !decimalPattern.MatchString(s) {
+		return nil, fmt.Errorf("%q is not a decimal number", s)
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a decimal number", s)
+	}
+
+	return r, nil
+}
+
+// decimalFromFloat64 converts f to the decimal with the fewest digits which
+// parses back to f, so 1.005 is treated as 1.005 rather than the nearest
+// binary value, 1.00499999999999989...
+func decimalFromFloat64(f float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+
+	return r
+}
+
+// addTax returns price plus tax at rate, such as 0.085 for 8.5%.
+func addTax(price, rate *big.Rat) *big.Rat {
+	tax := new(big.Rat).Mul(price, rate)
+
+	return tax.Add(tax, price)
+}
+
+// roundDecimal rounds x to precision decimal places using mode.
+func roundDecimal(x *big.Rat, precision int, mode roundingMode) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil)
+	scaled := new(big.Rat).Mul(x, new(big.Rat).SetInt(scale))
+
+	// Rat denominators are always positive, so DivMod returns the floor of
+	// the quotient and a non-negative remainder.
+	quotient, remainder := new(big.Int).DivMod(scaled.Num(), scaled.Denom(), new(big.Int))
+
+	// Compare twice the remainder with the denominator to find whether the
+	// discarded fraction is below, at or above one half.
+	half := new(big.Int).Lsh(remainder, 1).Cmp(scaled.Denom())
+
+	switch mode {
+	case roundingModeHalfUp:
+		if half > 0 || (half == 0 && x.Sign() > 0) {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	case roundingModeHalfEven:
+		if half > 0 || (half == 0 && quotient.Bit(0) == 1) {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	case roundingModeFloor:
+	}
+
+	return new(big.Rat).SetFrac(quotient, scale)
+}
+
+// roundCents rounds amount half-up to two decimal places. All functions
+// which compute prices as numbers use it, so their results are consistent.
+func roundCents(amount *big.Rat) float64 {
+	f, _ := roundDecimal(amount, 2, roundingModeHalfUp).Float64()
+
+	return f
+}
@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestRoundDecimal(t *testing.T) {
+	testCases := map[string]struct {
+		value     string
+		precision int
+		mode      roundingMode
+		expected  string
+	}{
+		"half-up-half": {
+			value:     "1.005",
+			precision: 2,
+			mode:      roundingModeHalfUp,
+			expected:  "1.01",
+		},
+		"half-up-below-half": {
+			value:     "1.0049",
+			precision: 2,
+			mode:      roundingModeHalfUp,
+			expected:  "1.00",
+		},
+		"half-up-negative-half": {
+			value:     "-1.005",
+			precision: 2,
+			mode:      roundingModeHalfUp,
+			expected:  "-1.01",
+		},
+		"half-up-negative-below-half": {
+			value:     "-1.004",
+			precision: 2,
+			mode:      roundingModeHalfUp,
+			expected:  "-1.00",
+		},
+		"half-even-half-to-even": {
+			value:     "1.005",
+			precision: 2,
+			mode:      roundingModeHalfEven,
+			expected:  "1.00",
+		},
+		"half-even-half-to-odd": {
+			value:     "1.015",
+			precision: 2,
+			mode:      roundingModeHalfEven,
+			expected:  "1.02",
+		},
+		"half-even-above-half": {
+			value:     "1.0051",
+			precision: 2,
+			mode:      roundingModeHalfEven,
+			expected:  "1.01",
+		},
+		"half-even-negative-half": {
+			value:     "-2.5",
+			precision: 0,
+			mode:      roundingModeHalfEven,
+			expected:  "-2",
+		},
+		"floor": {
+			value:     "1.009",
+			precision: 2,
+			mode:      roundingModeFloor,
+			expected:  "1.00",
+		},
+		"floor-negative": {
+			value:     "-1.001",
+			precision: 2,
+			mode:      roundingModeFloor,
+			expected:  "-1.01",
+		},
+		"zero-precision": {
+			value:     "542.5",
+			precision: 0,
+			mode:      roundingModeHalfUp,
+			expected:  "543",
+		},
+		"three-decimal-currency": {
+			value:     "1.23456",
+			precision: 3,
+			mode:      roundingModeHalfUp,
+			expected:  "1.235",
+		},
+		"exact": {
+			value:     "5.43",
+			precision: 2,
+			mode:      roundingModeFloor,
+			expected:  "5.43",
+		},
+		"exponent": {
+			value:     "1.2345e2",
+			precision: 1,
+			mode:      roundingModeHalfUp,
+			expected:  "123.5",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			value, err := parseDecimal(testCase.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			got := roundDecimal(value, testCase.precision, testCase.mode).FloatString(testCase.precision)
+
+			if got != testCase.expected {
+				t.Errorf("expected %s, got %s", testCase.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseDecimal_Invalid(t *testing.T) {
+	for _, value := range []string{"", "abc", "1/3", "1.0.0", "NaN", "Inf", "0x10"} {
+		t.Run(value, func(t *testing.T) {
+			if _, err := parseDecimal(value); err == nil {
+				t.Errorf("expected error for %q", value)
+			}
+		})
+	}
+}
+
+func TestRoundCents(t *testing.T) {
+	testCases := map[string]struct {
+		price    float64
+		rate     float64
+		expected float64
+	}{
+		"compute-tax-example": {
+			price:    5.00,
+			rate:     0.085,
+			expected: 5.43,
+		},
+		"binary-representation": {
+			// 1.005 is stored as 1.00499999999999989..., which rounds
+			// down with float64 arithmetic.
+			price:    1.005,
+			rate:     0,
+			expected: 1.01,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := roundCents(addTax(decimalFromFloat64(testCase.price), decimalFromFloat64(testCase.rate)))
+
+			if got != testCase.expected {
+				t.Errorf("expected %v, got %v", testCase.expected, got)
+			}
+		})
+	}
+}
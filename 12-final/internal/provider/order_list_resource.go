@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp-demoapp/hashicups-client-go"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ list.ListResource              = &orderListResource{}
+	_ list.ListResourceWithConfigure = &orderListResource{}
+)
+
+// NewOrderListResource is a helper function to simplify the provider implementation.
+func NewOrderListResource() list.ListResource {
+	return &orderListResource{}
+}
+
+// orderListResource is the list resource implementation. It allows
+// existing orders to be found with `terraform query` and imported.
+type orderListResource struct {
+	client *hashicups.Client
+}
+
+// Metadata returns the list resource type name, which must match the
+// managed resource type name.
+func (r *orderListResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_order"
+}
+
+// ListResourceConfigSchema defines the schema for list blocks.
+func (r *orderListResource) ListResourceConfigSchema(_ context.Context, _ list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists every order of the configured user.",
+	}
+}
+
+// List streams every order of the configured user.
+func (r *orderListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	// Terraform does not defer list resources, so the client is missing
+	// when the provider configuration was deferred.
+	if r.client == nil {
+		stream.Results = list.ListResultsStreamDiagnostics(diag.Diagnostics{
+			diag.NewErrorDiagnostic(
+				"Unconfigured HashiCups API Client",
+				"Orders cannot be listed as the provider configuration has values which are not known yet. "+
+					"Apply the configuration the provider configuration depends on first.",
+			),
+		})
+		return
+	}
+
+	orders, err := clientWithContext(ctx, r.client).ListOrders()
+	if err != nil {
+		stream.Results = list.ListResultsStreamDiagnostics(diag.Diagnostics{
+			clientErrorDiagnostic(
+				err,
+				"Error Listing HashiCups Orders",
+				"Could not list HashiCups orders, unexpected error: "+err.Error(),
+			),
+		})
+		return
+	}
+
+	tflog.Debug(ctx, "Listed HashiCups orders", map[string]any{
+		"order_count": len(orders),
+	})
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		for index, order := range orders {
+			if req.Limit > 0 && int64(index) >= req.Limit {
+				return
+			}
+
+			result := req.NewListResult(ctx)
+			result.DisplayName = fmt.Sprintf("Order %d", order.ID)
+
+			id := types.StringValue(strconv.Itoa(order.ID))
+
+			result.Diagnostics.Append(result.Identity.Set(ctx, orderResourceIdentityModel{
+				ID:   id,
+				Host: types.StringValue(r.client.HostURL),
+			})...)
+
+			if req.IncludeResource {
+				state := orderResourceModel{
+					ID:          id,
+					Items:       []orderItemModel{},
+					LastUpdated: types.StringNull(),
+				}
+
+				for _, item := range order.Items {
+					state.Items = append(state.Items, orderItemModel{
+						Coffee: orderItemCoffeeModel{
+							ID:          types.Int64Value(int64(item.Coffee.ID)),
+							Name:        types.StringValue(item.Coffee.Name),
+							Teaser:      types.StringValue(item.Coffee.Teaser),
+							Description: types.StringValue(item.Coffee.Description),
+							Price:       types.Float64Value(item.Coffee.Price),
+							Image:       types.StringValue(item.Coffee.Image),
+						},
+						Quantity: types.Int64Value(int64(item.Quantity)),
+					})
+				}
+
+				result.Diagnostics.Append(result.Resource.Set(ctx, state)...)
+			}
+
+			if !push(result) {
+				return
+			}
+		}
+	}
+}
+
+// Configure adds the provider configured client to the list resource.
+func (r *orderListResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected List Resource Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = data.client
+}
@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/querycheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccOrderListResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_14_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create an order to list
+			{
+				Config: providerConfig + `
+resource "hashicups_order" "test" {
+  items = [
+    {
+      coffee = {
+        id = 1
+      }
+      quantity = 2
+    },
+  ]
+}
+`,
+			},
+			// Query testing
+			{
+				Query: true,
+				Config: providerConfig + `
+list "hashicups_order" "test" {
+  provider = hashicups
+}
+`,
+				QueryResultChecks: []querycheck.QueryResultCheck{
+					querycheck.ExpectLengthAtLeast("hashicups_order.test", 1),
+					querycheck.ExpectIdentity("hashicups_order.test", map[string]knownvalue.Check{
+						"id":   knownvalue.NotNull(),
+						"host": knownvalue.StringExact("http://localhost:19090"),
+					}),
+				},
+			},
+		},
+	})
+}
+
+func TestOrderListResource_List(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	for _, quantity := range []int{1, 2, 3} {
+		created := server.create("hashicups_order", orderConfig(1, quantity))
+		server.requireNoErrors(created.Diagnostics)
+	}
+
+	identityType := server.identityType("hashicups_order")
+	stateType := server.resourceType("hashicups_order")
+
+	results := server.list("hashicups_order", true, 0)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	for index, result := range results {
+		server.requireNoErrors(result.Diagnostics)
+
+		id := fmt.Sprint(index + 1)
+
+		if expected := "Order " + id; result.DisplayName != expected {
+			t.Errorf("expected display name %q, got %q", expected, result.DisplayName)
+		}
+
+		if got := server.stringAttribute(identityType, result.Identity.IdentityData, "id"); got != id {
+			t.Errorf("expected identity id %q, got %q", id, got)
+		}
+
+		if got := server.stringAttribute(identityType, result.Identity.IdentityData, "host"); got != api.URL() {
+			t.Errorf("expected identity host %q, got %q", api.URL(), got)
+		}
+
+		if got, _ := server.numberAttribute(stateType, result.Resource, "items", 0, "quantity").Int64(); got != int64(index+1) {
+			t.Errorf("expected quantity %d, got %d", index+1, got)
+		}
+
+		if got := server.stringAttribute(stateType, result.Resource, "items", 0, "coffee", "name"); got != "HCP Aeropress" {
+			t.Errorf("expected coffee name %q, got %q", "HCP Aeropress", got)
+		}
+	}
+}
+
+func TestOrderListResource_ListLimit(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	for range 3 {
+		created := server.create("hashicups_order", orderConfig(1, 1))
+		server.requireNoErrors(created.Diagnostics)
+	}
+
+	results := server.list("hashicups_order", false, 2)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	for _, result := range results {
+		server.requireNoErrors(result.Diagnostics)
+
+		if result.Resource != nil {
+			t.Errorf("expected no resource when not requested, got: %v", result.Resource)
+		}
+	}
+}
+
+func TestOrderListResource_ListAPIError(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	api.handle("GET /orders", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "database unavailable", http.StatusInternalServerError)
+	})
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	results := server.list("hashicups_order", true, 0)
+	if len(results) != 1 {
+		t.Fatalf("expected a single diagnostics result, got %d results", len(results))
+	}
+
+	detail := server.requireError(results[0].Diagnostics, "Error Listing HashiCups Orders")
+	if !strings.Contains(detail, "database unavailable") {
+		t.Errorf("expected detail to contain the response body, got: %s", detail)
+	}
+}
+
+func TestOrderListResource_ListUnconfigured(t *testing.T) {
+	server := newUnconfiguredTestProviderServer(t, context.Background())
+
+	resp := server.configureWithCapabilities(map[string]any{"host": tftypes.UnknownValue}, &tfprotov6.ConfigureProviderClientCapabilities{
+		DeferralAllowed: true,
+	})
+	server.requireNoErrors(resp.Diagnostics)
+
+	results := server.list("hashicups_order", true, 0)
+	if len(results) != 1 {
+		t.Fatalf("expected a single diagnostics result, got %d results", len(results))
+	}
+
+	server.requireError(results[0].Diagnostics, "Unconfigured HashiCups API Client")
+}
@@ -2,17 +2,23 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/hashicorp-demoapp/hashicups-client-go"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -20,6 +26,8 @@ var (
 	_ resource.Resource                = &orderResource{}
 	_ resource.ResourceWithConfigure   = &orderResource{}
 	_ resource.ResourceWithImportState = &orderResource{}
+	_ resource.ResourceWithIdentity    = &orderResource{}
+	_ resource.ResourceWithModifyPlan  = &orderResource{}
 )
 
 // NewOrderResource is a helper function to simplify the provider implementation.
@@ -34,6 +42,12 @@ type orderResourceModel struct {
 	LastUpdated types.String     `tfsdk:"last_updated"`
 }
 
+// orderResourceIdentityModel maps the resource identity data.
+type orderResourceIdentityModel struct {
+	ID   types.String `tfsdk:"id"`
+	Host types.String `tfsdk:"host"`
+}
+
 // orderItemModel maps order item data.
 type orderItemModel struct {
 	Coffee   orderItemCoffeeModel `tfsdk:"coffee"`
@@ -52,7 +66,8 @@ type orderItemCoffeeModel struct {
 
 // orderResource is the resource implementation.
 type orderResource struct {
-	client *hashicups.Client
+	client  *hashicups.Client
+	coffees *coffeeCache
 }
 
 // Metadata returns the resource type name.
@@ -122,8 +137,29 @@ func (r *orderResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 	}
 }
 
+// IdentitySchema defines the identity schema for the resource. Order IDs
+// are only unique within a HashiCups instance, so the identity includes the
+// API host.
+func (r *orderResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"id": identityschema.StringAttribute{
+				Description:       "Numeric identifier of the order.",
+				RequiredForImport: true,
+			},
+			"host": identityschema.StringAttribute{
+				Description:       "URI for HashiCups API the order was placed with. Defaults to the provider host.",
+				OptionalForImport: true,
+			},
+		},
+	}
+}
+
 // Create a new resource.
 func (r *orderResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := startSpan(ctx, "hashicups_order.Create")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
 	// Retrieve values from plan
 	var plan orderResourceModel
 	diags := req.Plan.Get(ctx, &plan)
@@ -144,12 +180,17 @@ func (r *orderResource) Create(ctx context.Context, req resource.CreateRequest,
 	}
 
 	// Create new order
-	order, err := r.client.CreateOrder(items)
+	tflog.Debug(ctx, "Creating HashiCups order", map[string]any{
+		"item_count": len(items),
+	})
+
+	order, err := clientWithContext(ctx, r.client).CreateOrder(items)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(clientErrorDiagnostic(
+			err,
 			"Error creating order",
 			"Could not create order, unexpected error: "+err.Error(),
-		)
+		))
 		return
 	}
 
@@ -170,16 +211,30 @@ func (r *orderResource) Create(ctx context.Context, req resource.CreateRequest,
 	}
 	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
 
+	ctx = tflog.SetField(ctx, "order_id", plan.ID.ValueString())
+	span.SetAttributes(attribute.String("hashicups.order.id", plan.ID.ValueString()))
+	tflog.Info(ctx, "Created HashiCups order")
+
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	// Set identity
+	diags = resp.Identity.Set(ctx, r.identity(plan.ID))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 }
 
 // Read resource information.
 func (r *orderResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := startSpan(ctx, "hashicups_order.Read")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
 	// Get current state
 	var state orderResourceModel
 	diags := req.State.Get(ctx, &state)
@@ -188,13 +243,26 @@ func (r *orderResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
+	ctx = tflog.SetField(ctx, "order_id", state.ID.ValueString())
+	span.SetAttributes(attribute.String("hashicups.order.id", state.ID.ValueString()))
+
 	// Get refreshed order value from HashiCups
-	order, err := r.client.GetOrder(state.ID.ValueString())
+	order, err := r.getOrder(ctx, state.ID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(clientErrorDiagnostic(
+			err,
 			"Error Reading HashiCups Order",
 			"Could not read HashiCups order ID "+state.ID.ValueString()+": "+err.Error(),
-		)
+		))
+		return
+	}
+
+	// Remove orders deleted outside of Terraform from state, so the next
+	// plan recreates them.
+	if order == nil {
+		tflog.Warn(ctx, "HashiCups order not found, removing from state")
+
+		resp.State.RemoveResource(ctx)
 		return
 	}
 
@@ -220,9 +288,20 @@ func (r *orderResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	// Set identity, which also populates it for orders created before
+	// identity was supported
+	diags = resp.Identity.Set(ctx, r.identity(state.ID))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 }
 
 func (r *orderResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx, span := startSpan(ctx, "hashicups_order.Update")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
 	// Retrieve values from plan
 	var plan orderResourceModel
 	diags := req.Plan.Get(ctx, &plan)
@@ -242,24 +321,33 @@ func (r *orderResource) Update(ctx context.Context, req resource.UpdateRequest,
 		})
 	}
 
+	ctx = tflog.SetField(ctx, "order_id", plan.ID.ValueString())
+	span.SetAttributes(attribute.String("hashicups.order.id", plan.ID.ValueString()))
+
 	// Update existing order
-	_, err := r.client.UpdateOrder(plan.ID.ValueString(), hashicupsItems)
+	tflog.Debug(ctx, "Updating HashiCups order", map[string]any{
+		"item_count": len(hashicupsItems),
+	})
+
+	_, err := clientWithContext(ctx, r.client).UpdateOrder(plan.ID.ValueString(), hashicupsItems)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(clientErrorDiagnostic(
+			err,
 			"Error Updating HashiCups Order",
 			"Could not update order, unexpected error: "+err.Error(),
-		)
+		))
 		return
 	}
 
 	// Fetch updated items from GetOrder as UpdateOrder items are not
 	// populated.
-	order, err := r.client.GetOrder(plan.ID.ValueString())
+	order, err := clientWithContext(ctx, r.client).GetOrder(plan.ID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(clientErrorDiagnostic(
+			err,
 			"Error Reading HashiCups Order",
 			"Could not read HashiCups order ID "+plan.ID.ValueString()+": "+err.Error(),
-		)
+		))
 		return
 	}
 
@@ -280,14 +368,25 @@ func (r *orderResource) Update(ctx context.Context, req resource.UpdateRequest,
 	}
 	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
 
+	tflog.Info(ctx, "Updated HashiCups order")
+
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	diags = resp.Identity.Set(ctx, r.identity(plan.ID))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 }
 
 func (r *orderResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := startSpan(ctx, "hashicups_order.Delete")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
 	// Retrieve values from state
 	var state orderResourceModel
 	diags := req.State.Get(ctx, &state)
@@ -296,15 +395,21 @@ func (r *orderResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
+	ctx = tflog.SetField(ctx, "order_id", state.ID.ValueString())
+	span.SetAttributes(attribute.String("hashicups.order.id", state.ID.ValueString()))
+
 	// Delete existing order
-	err := r.client.DeleteOrder(state.ID.ValueString())
+	err := clientWithContext(ctx, r.client).DeleteOrder(state.ID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(clientErrorDiagnostic(
+			err,
 			"Error Deleting HashiCups Order",
 			"Could not delete order, unexpected error: "+err.Error(),
-		)
+		))
 		return
 	}
+
+	tflog.Info(ctx, "Deleted HashiCups order")
 }
 
 // Configure adds the provider configured client to the resource.
@@ -315,21 +420,188 @@ func (r *orderResource) Configure(_ context.Context, req resource.ConfigureReque
 		return
 	}
 
-	client, ok := req.ProviderData.(*hashicups.Client)
+	data, ok := req.ProviderData.(*providerData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *hashicups.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.client
+	r.coffees = data.coffees
+}
+
+// ModifyPlan checks that the ordered coffees are in the catalog, so an
+// unknown coffee fails the plan rather than the apply. The catalog is only
+// checked when the coffee cache is enabled, so plans send no additional
+// requests by default.
+func (r *orderResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil || !r.coffees.enabled() {
+		return
+	}
+
+	var plan orderResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	coffees, err := r.coffees.GetCoffees(ctx, r.client)
+	if err != nil {
+		resp.Diagnostics.Append(clientErrorDiagnostic(
+			err,
+			"Unable to Read HashiCups Coffees",
+			"Could not read the coffee catalog to check the ordered coffees: "+err.Error(),
+		))
+		return
+	}
+
+	catalog := make(map[int64]bool, len(coffees))
+	for _, coffee := range coffees {
+		catalog[int64(coffee.ID)] = true
+	}
+
+	for i, item := range plan.Items {
+		if item.Coffee.ID.IsUnknown() || item.Coffee.ID.IsNull() || catalog[item.Coffee.ID.ValueInt64()] {
+			continue
+		}
+
+		resp.Diagnostics.AddAttributeError(
+			path.Root("items").AtListIndex(i).AtName("coffee").AtName("id"),
+			"Unknown HashiCups Coffee",
+			fmt.Sprintf("The HashiCups coffee catalog has no coffee with ID %d. "+
+				"Use the hashicups_coffees data source to list the available coffees.", item.Coffee.ID.ValueInt64()),
+		)
+	}
 }
 
 func (r *orderResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	ctx, span := startSpan(ctx, "hashicups_order.ImportState")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	importID := req.ID
+
+	// Import blocks with an identity, rather than an ID, are supported
+	// with Terraform 1.12 and later.
+	if importID == "" {
+		var identity orderResourceIdentityModel
+		resp.Diagnostics.Append(req.Identity.Get(ctx, &identity)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if !identity.Host.IsNull() {
+			host := identity.Host.ValueString()
+
+			if err := validateHost(host); err != nil {
+				resp.Diagnostics.AddError(
+					"Invalid HashiCups Order Identity Host",
+					fmt.Sprintf("The identity host %q is not valid: %s.", host, err),
+				)
+				return
+			}
+
+			// The provider host is normalized during configuration, so
+			// the identity host is too before they are compared.
+			if normalizeHost(host) != r.client.HostURL {
+				resp.Diagnostics.AddError(
+					"Mismatched HashiCups Order Identity Host",
+					fmt.Sprintf("The identity host %q does not match the host the provider is configured with, %q. "+
+						"Import the order with a provider configured for its host.", host, r.client.HostURL),
+				)
+				return
+			}
+		}
+
+		importID = identity.ID.ValueString()
+	}
+
+	ctx = tflog.SetField(ctx, "import_id", importID)
+	span.SetAttributes(attribute.String("hashicups.import.id", importID))
+
+	// Reject invalid IDs now, rather than failing later during Read.
+	orderID, err := parseOrderID(importID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid HashiCups Order Import ID",
+			"The import ID must be the numeric identifier of an existing order, such as \"1\": "+err.Error(),
+		)
+		return
+	}
+
+	// Orders are scoped to the user, so this also verifies that the order
+	// belongs to the configured user.
+	order, err := r.getOrder(ctx, strconv.Itoa(orderID))
+	if err != nil {
+		resp.Diagnostics.Append(clientErrorDiagnostic(
+			err,
+			"Error Importing HashiCups Order",
+			"Could not read HashiCups order ID "+importID+": "+err.Error(),
+		))
+		return
+	}
+
+	if order == nil {
+		resp.Diagnostics.Append(r.orderNotFoundDiagnostic(orderID))
+		return
+	}
+
+	// Save the normalized import ID to id attribute and identity
+	id := types.StringValue(strconv.Itoa(orderID))
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, r.identity(id))...)
+}
+
+// getOrder returns the order with the given ID, or nil if the signed in
+// user has no such order. The HashiCups API responds with 404 Not Found for
+// some missing orders, and with an empty order for others.
+func (r *orderResource) getOrder(ctx context.Context, orderID string) (*hashicups.Order, error) {
+	order, err := clientWithContext(ctx, r.client).GetOrder(orderID)
+	if err != nil {
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if strconv.Itoa(order.ID) != orderID {
+		return nil, nil
+	}
+
+	return order, nil
+}
+
+// identity returns the resource identity of the order with the given ID.
+func (r *orderResource) identity(id types.String) orderResourceIdentityModel {
+	return orderResourceIdentityModel{
+		ID:   id,
+		Host: types.StringValue(r.client.HostURL),
+	}
+}
+
+// parseOrderID parses an order ID, which is a positive integer.
+func parseOrderID(s string) (int, error) {
+	orderID, err := strconv.Atoi(s)
+	if err != nil || orderID <= 0 {
+		return 0, fmt.Errorf("%q is not a valid order ID", s)
+	}
+
+	return orderID, nil
+}
+
+// orderNotFoundDiagnostic returns the diagnostic for importing an order
+// which does not exist or belongs to another user.
+func (r *orderResource) orderNotFoundDiagnostic(orderID int) diag.Diagnostic {
+	return diag.NewErrorDiagnostic(
+		"HashiCups Order Not Found",
+		fmt.Sprintf("Could not import HashiCups order ID %d: no such order belongs to user %q. "+
+			"Check the order ID and that the provider is configured with the user who placed the order.", orderID, r.client.Auth.Username),
+	)
 }
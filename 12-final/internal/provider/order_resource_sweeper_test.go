@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp-demoapp/hashicups-client-go"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func init() {
+	resource.AddTestSweepers("hashicups_order", &resource.Sweeper{
+		Name: "hashicups_order",
+		F: func(_ string) error {
+			client, err := sharedClient()
+			if err != nil {
+				return fmt.Errorf("error creating HashiCups client: %w", err)
+			}
+
+			return sweepOrders(context.Background(), client)
+		},
+	})
+}
+
+// sweepOrders deletes every order of the user client is signed in as.
+// Orders are scoped to the user, so only orders left behind by acceptance
+// tests run as that user are deleted.
+func sweepOrders(ctx context.Context, client *hashicups.Client) error {
+	orders, err := clientWithContext(ctx, client).ListOrders()
+	if err != nil {
+		return fmt.Errorf("error listing HashiCups orders: %w", err)
+	}
+
+	var errs []error
+
+	for _, order := range orders {
+		log.Printf("[INFO] Deleting HashiCups order %d", order.ID)
+
+		err := clientWithContext(ctx, client).DeleteOrder(strconv.Itoa(order.ID))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error deleting HashiCups order %d: %w", order.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func TestSweepOrders(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	api.setOrder(hashicups.Order{ID: 1, Items: []hashicups.OrderItem{{Coffee: hashicups.Coffee{ID: 1}, Quantity: 1}}})
+	api.setOrder(hashicups.Order{ID: 2, Items: []hashicups.OrderItem{{Coffee: hashicups.Coffee{ID: 2}, Quantity: 2}}})
+
+	client, err := newHashicupsClient(context.Background(), api.URL(), fakeAPIUsername, fakeAPIPassword, newHTTPClient(httpClientConfig{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = sweepOrders(context.Background(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, id := range []int{1, 2} {
+		if _, ok := api.order(id); ok {
+			t.Errorf("expected order %d to be deleted", id)
+		}
+	}
+}
+
+func TestSweepOrders_DeleteError(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	api.setOrder(hashicups.Order{ID: 1, Items: []hashicups.OrderItem{{Coffee: hashicups.Coffee{ID: 1}, Quantity: 1}}})
+	api.setOrder(hashicups.Order{ID: 2, Items: []hashicups.OrderItem{{Coffee: hashicups.Coffee{ID: 2}, Quantity: 2}}})
+	api.inject(fakeAPIFault{
+		Method:     http.MethodDelete,
+		Path:       "/orders/1",
+		StatusCode: http.StatusInternalServerError,
+	})
+
+	client, err := newHashicupsClient(context.Background(), api.URL(), fakeAPIUsername, fakeAPIPassword, newHTTPClient(httpClientConfig{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = sweepOrders(context.Background(), client)
+	if err == nil || !strings.Contains(err.Error(), "error deleting HashiCups order 1") {
+		t.Errorf("expected error deleting order 1, got: %v", err)
+	}
+
+	// Sweeping continues after an error.
+	if _, ok := api.order(2); ok {
+		t.Error("expected order 2 to be deleted")
+	}
+}
@@ -1,12 +1,29 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp-demoapp/hashicups-client-go"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
 )
 
 func TestAccOrderResource(t *testing.T) {
+	// orderID is the ID of the order created by the first step, so later
+	// steps can change it outside of Terraform.
+	var orderID string
+
 	resource.Test(t, resource.TestCase{
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
@@ -39,7 +56,32 @@ resource "hashicups_order" "test" {
 					// Verify dynamic values have any value set in the state.
 					resource.TestCheckResourceAttrSet("hashicups_order.test", "id"),
 					resource.TestCheckResourceAttrSet("hashicups_order.test", "last_updated"),
+					resource.TestCheckResourceAttrWith("hashicups_order.test", "id", func(value string) error {
+						orderID = value
+						return nil
+					}),
 				),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("hashicups_order.test", tfjsonpath.New("items"), knownvalue.ListExact([]knownvalue.Check{
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"quantity": knownvalue.Int64Exact(2),
+							"coffee": knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"id":          knownvalue.Int64Exact(1),
+								"name":        knownvalue.StringExact("HCP Aeropress"),
+								"teaser":      knownvalue.StringExact("Automation in a cup"),
+								"description": knownvalue.StringExact(""),
+								"price":       knownvalue.Float64Exact(200),
+								"image":       knownvalue.StringExact("/hashicorp.png"),
+							}),
+						}),
+					})),
+				},
+				// Verify Read maps the order exactly as Create did
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PostApplyPostRefresh: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
 			},
 			// ImportState testing
 			{
@@ -50,6 +92,20 @@ resource "hashicups_order" "test" {
 				// API, therefore there is no value for it during import.
 				ImportStateVerifyIgnore: []string{"last_updated"},
 			},
+			// ImportState testing with an ID which is not numeric
+			{
+				ResourceName:  "hashicups_order.test",
+				ImportState:   true,
+				ImportStateId: "abc",
+				ExpectError:   regexp.MustCompile(`Invalid HashiCups Order Import ID`),
+			},
+			// ImportState testing with an order which does not exist
+			{
+				ResourceName:  "hashicups_order.test",
+				ImportState:   true,
+				ImportStateId: "999999",
+				ExpectError:   regexp.MustCompile(`HashiCups Order Not Found`),
+			},
 			// Update and Read testing
 			{
 				Config: providerConfig + `
@@ -75,8 +131,725 @@ resource "hashicups_order" "test" {
 					resource.TestCheckResourceAttr("hashicups_order.test", "items.0.coffee.price", "350"),
 					resource.TestCheckResourceAttr("hashicups_order.test", "items.0.coffee.teaser", "Packed with goodness to spice up your images"),
 				),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("hashicups_order.test", plancheck.ResourceActionUpdate),
+					},
+					PostApplyPostRefresh: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+			// Drift testing with an order changed outside of Terraform
+			{
+				PreConfig: func() {
+					_, err := clientWithContext(context.Background(), testAccClient(t)).UpdateOrder(orderID, []hashicups.OrderItem{
+						{Coffee: hashicups.Coffee{ID: 3}, Quantity: 5},
+					})
+					if err != nil {
+						t.Fatalf("error changing order outside of Terraform: %s", err)
+					}
+				},
+				Config: providerConfig + `
+resource "hashicups_order" "test" {
+  items = [
+    {
+      coffee = {
+        id = 2
+      }
+      quantity = 2
+    },
+  ]
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("hashicups_order.test", plancheck.ResourceActionUpdate),
+					},
+					PostApplyPostRefresh: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("hashicups_order.test", tfjsonpath.New("items").AtSliceIndex(0).AtMapKey("coffee").AtMapKey("id"), knownvalue.Int64Exact(2)),
+					statecheck.ExpectKnownValue("hashicups_order.test", tfjsonpath.New("items").AtSliceIndex(0).AtMapKey("quantity"), knownvalue.Int64Exact(2)),
+				},
+			},
+			// Drift testing with an order deleted outside of Terraform
+			{
+				PreConfig: func() {
+					err := clientWithContext(context.Background(), testAccClient(t)).DeleteOrder(orderID)
+					if err != nil {
+						t.Fatalf("error deleting order outside of Terraform: %s", err)
+					}
+				},
+				Config: providerConfig + `
+resource "hashicups_order" "test" {
+  items = [
+    {
+      coffee = {
+        id = 2
+      }
+      quantity = 2
+    },
+  ]
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("hashicups_order.test", plancheck.ResourceActionCreate),
+					},
+					PostApplyPostRefresh: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
 			},
 			// Delete testing automatically occurs in TestCase
 		},
 	})
 }
+
+func TestAccOrderResource_Identity(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_12_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "hashicups_order" "test" {
+  items = [
+    {
+      coffee = {
+        id = 1
+      }
+      quantity = 2
+    },
+  ]
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectIdentity("hashicups_order.test", map[string]knownvalue.Check{
+						"id":   knownvalue.NotNull(),
+						"host": knownvalue.StringExact("http://localhost:19090"),
+					}),
+					statecheck.ExpectIdentityValueMatchesState("hashicups_order.test", tfjsonpath.New("id")),
+				},
+			},
+			// ImportState testing with an import block using the identity
+			{
+				ResourceName:    "hashicups_order.test",
+				ImportState:     true,
+				ImportStateKind: resource.ImportBlockWithResourceIdentity,
+			},
+		},
+	})
+}
+
+// orderConfig returns the hashicups_order configuration for the given
+// coffee ID and quantity pairs.
+func orderConfig(coffeeQuantities ...int) map[string]any {
+	items := []any{}
+
+	for i := 0; i+1 < len(coffeeQuantities); i += 2 {
+		items = append(items, map[string]any{
+			"coffee": map[string]any{
+				"id": coffeeQuantities[i],
+			},
+			"quantity": coffeeQuantities[i+1],
+		})
+	}
+
+	return map[string]any{
+		"items": items,
+	}
+}
+
+func TestOrderResource_Lifecycle(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+	stateType := server.resourceType("hashicups_order")
+	identityType := server.identityType("hashicups_order")
+
+	// Create
+	created := server.create("hashicups_order", orderConfig(1, 2))
+	server.requireNoErrors(created.Diagnostics)
+
+	if got := server.stringAttribute(stateType, created.NewState, "id"); got != "1" {
+		t.Errorf("expected id %q, got %q", "1", got)
+	}
+
+	if got := server.stringAttribute(stateType, created.NewState, "items", 0, "coffee", "name"); got != "HCP Aeropress" {
+		t.Errorf("expected coffee name %q, got %q", "HCP Aeropress", got)
+	}
+
+	if got := server.stringAttribute(identityType, created.NewIdentity.IdentityData, "host"); got != api.URL() {
+		t.Errorf("expected identity host %q, got %q", api.URL(), got)
+	}
+
+	// Read
+	read := server.read("hashicups_order", created.NewState, created.NewIdentity)
+	server.requireNoErrors(read.Diagnostics)
+
+	if got := server.stringAttribute(identityType, read.NewIdentity.IdentityData, "id"); got != "1" {
+		t.Errorf("expected identity id %q, got %q", "1", got)
+	}
+
+	// Update
+	updated := server.update("hashicups_order", read.NewState, orderConfig(2, 1, 3, 4))
+	server.requireNoErrors(updated.Diagnostics)
+
+	if got := server.stringAttribute(stateType, updated.NewState, "items", 1, "coffee", "name"); got != "Vaulatte" {
+		t.Errorf("expected coffee name %q, got %q", "Vaulatte", got)
+	}
+
+	if got, _ := server.numberAttribute(stateType, updated.NewState, "items", 1, "quantity").Int64(); got != 4 {
+		t.Errorf("expected quantity 4, got %d", got)
+	}
+
+	if order, _ := api.order(1); len(order.Items) != 2 {
+		t.Errorf("expected 2 items in the stored order, got %d", len(order.Items))
+	}
+
+	// Delete
+	deleted := server.delete("hashicups_order", updated.NewState)
+	server.requireNoErrors(deleted.Diagnostics)
+
+	if !server.isNull(stateType, deleted.NewState) {
+		t.Error("expected null state after delete")
+	}
+
+	if _, ok := api.order(1); ok {
+		t.Error("expected order to be deleted")
+	}
+}
+
+func TestOrderResource_CreateAPIError(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	api.handle("POST /orders", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "database unavailable", http.StatusInternalServerError)
+	})
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	resp := server.create("hashicups_order", orderConfig(1, 2))
+
+	detail := server.requireError(resp.Diagnostics, "Error creating order")
+	if !strings.Contains(detail, "database unavailable") {
+		t.Errorf("expected detail to contain the response body, got: %s", detail)
+	}
+
+	if !server.isNull(server.resourceType("hashicups_order"), resp.NewState) {
+		t.Error("expected null state after failed create")
+	}
+}
+
+func TestOrderResource_CreateValidationError(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	api.handle("POST /orders", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid coffee", http.StatusUnprocessableEntity)
+	})
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	resp := server.create("hashicups_order", orderConfig(99, 1))
+
+	server.requireError(resp.Diagnostics, "HashiCups API Validation Failed")
+}
+
+func TestOrderResource_CreatePartialResponse(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	api.handle("POST /orders", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":1,"items":[{"coffee":{"id":1`))
+	})
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	resp := server.create("hashicups_order", orderConfig(1, 2))
+
+	detail := server.requireError(resp.Diagnostics, "Error creating order")
+	if !strings.Contains(detail, "unexpected end of JSON input") {
+		t.Errorf("expected detail to describe the truncated response, got: %s", detail)
+	}
+}
+
+func TestOrderResource_PlanCoffees(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL(), "coffee_cache_ttl": "5m"})
+
+	resourceType := server.resourceType("hashicups_order")
+
+	planned := server.plan("hashicups_order", tftypes.NewValue(resourceType, nil), server.value(resourceType, orderConfig(1, 2)))
+	server.requireNoErrors(planned.Diagnostics)
+
+	planned = server.plan("hashicups_order", tftypes.NewValue(resourceType, nil), server.value(resourceType, orderConfig(1, 2, 42, 1)))
+
+	detail := server.requireError(planned.Diagnostics, "Unknown HashiCups Coffee")
+	if !strings.Contains(detail, "no coffee with ID 42") {
+		t.Errorf("expected detail to name the coffee, got: %s", detail)
+	}
+
+	// Both plans are checked against the cached catalog.
+	if got := api.requestCount("GET /coffees"); got != 1 {
+		t.Errorf("expected 1 catalog request, got %d", got)
+	}
+}
+
+func TestOrderResource_PlanCoffeesCacheDisabled(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	resourceType := server.resourceType("hashicups_order")
+
+	planned := server.plan("hashicups_order", tftypes.NewValue(resourceType, nil), server.value(resourceType, orderConfig(42, 1)))
+	server.requireNoErrors(planned.Diagnostics)
+
+	if got := api.requestCount("GET /coffees"); got != 0 {
+		t.Errorf("expected no catalog requests, got %d", got)
+	}
+}
+
+func TestOrderResource_ReadNotFound(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	created := server.create("hashicups_order", orderConfig(1, 2))
+	server.requireNoErrors(created.Diagnostics)
+
+	api.deleteOrder(1)
+
+	resp := server.read("hashicups_order", created.NewState, created.NewIdentity)
+	server.requireNoErrors(resp.Diagnostics)
+
+	if !server.isNull(server.resourceType("hashicups_order"), resp.NewState) {
+		t.Error("expected order deleted outside of Terraform to be removed from state")
+	}
+}
+
+func TestOrderResource_ReadEmptyOrder(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	created := server.create("hashicups_order", orderConfig(1, 2))
+	server.requireNoErrors(created.Diagnostics)
+
+	// The HashiCups API responds with an empty order for some missing
+	// orders, rather than 404 Not Found.
+	api.handle("GET /orders/1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":0,"items":null}`))
+	})
+
+	resp := server.read("hashicups_order", created.NewState, created.NewIdentity)
+	server.requireNoErrors(resp.Diagnostics)
+
+	if !server.isNull(server.resourceType("hashicups_order"), resp.NewState) {
+		t.Error("expected missing order to be removed from state")
+	}
+}
+
+func TestOrderResource_ReadOutOfBandChange(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	created := server.create("hashicups_order", orderConfig(1, 2))
+	server.requireNoErrors(created.Diagnostics)
+
+	api.setOrder(hashicups.Order{
+		ID: 1,
+		Items: []hashicups.OrderItem{
+			{Coffee: hashicups.Coffee{ID: 2}, Quantity: 5},
+		},
+	})
+
+	resp := server.read("hashicups_order", created.NewState, created.NewIdentity)
+	server.requireNoErrors(resp.Diagnostics)
+
+	stateType := server.resourceType("hashicups_order")
+
+	if got := server.stringAttribute(stateType, resp.NewState, "items", 0, "coffee", "name"); got != "Packer Spiced Latte" {
+		t.Errorf("expected coffee name %q, got %q", "Packer Spiced Latte", got)
+	}
+
+	if got, _ := server.numberAttribute(stateType, resp.NewState, "items", 0, "quantity").Int64(); got != 5 {
+		t.Errorf("expected quantity 5, got %d", got)
+	}
+}
+
+func TestOrderResource_UpdateReadError(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	created := server.create("hashicups_order", orderConfig(1, 2))
+	server.requireNoErrors(created.Diagnostics)
+
+	// The update succeeds, but the order cannot be read back.
+	api.handle("GET /orders/1", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "database unavailable", http.StatusInternalServerError)
+	})
+
+	resp := server.update("hashicups_order", created.NewState, orderConfig(1, 3))
+
+	server.requireError(resp.Diagnostics, "Error Reading HashiCups Order")
+
+	if order, _ := api.order(1); order.Items[0].Quantity != 3 {
+		t.Errorf("expected stored quantity 3, got %d", order.Items[0].Quantity)
+	}
+}
+
+func TestOrderResource_DeleteUnexpectedResponse(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	created := server.create("hashicups_order", orderConfig(1, 2))
+	server.requireNoErrors(created.Diagnostics)
+
+	api.handle("DELETE /orders/1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("Order is being prepared"))
+	})
+
+	resp := server.delete("hashicups_order", created.NewState)
+
+	detail := server.requireError(resp.Diagnostics, "Error Deleting HashiCups Order")
+	if !strings.Contains(detail, "Order is being prepared") {
+		t.Errorf("expected detail to contain the response body, got: %s", detail)
+	}
+}
+
+func TestOrderResource_ImportState(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	api.setOrder(hashicups.Order{
+		ID: 7,
+		Items: []hashicups.OrderItem{
+			{Coffee: hashicups.Coffee{ID: 1}, Quantity: 1},
+		},
+	})
+
+	testCases := map[string]struct {
+		id              string
+		expectedError   string
+		expectedStateID string
+	}{
+		"valid": {
+			id:              "007",
+			expectedStateID: "7",
+		},
+		"invalid": {
+			id:            "abc",
+			expectedError: "Invalid HashiCups Order Import ID",
+		},
+		"not-found": {
+			id:            "8",
+			expectedError: "HashiCups Order Not Found",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+			resp := server.importState("hashicups_order", testCase.id)
+
+			if testCase.expectedError != "" {
+				server.requireError(resp.Diagnostics, testCase.expectedError)
+				return
+			}
+
+			server.requireNoErrors(resp.Diagnostics)
+
+			if len(resp.ImportedResources) != 1 {
+				t.Fatalf("expected 1 imported resource, got %d", len(resp.ImportedResources))
+			}
+
+			got := server.stringAttribute(server.resourceType("hashicups_order"), resp.ImportedResources[0].State, "id")
+			if got != testCase.expectedStateID {
+				t.Errorf("expected id %q, got %q", testCase.expectedStateID, got)
+			}
+		})
+	}
+}
+
+func TestOrderResource_ImportStateIdentity(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	api.setOrder(hashicups.Order{
+		ID: 7,
+		Items: []hashicups.OrderItem{
+			{Coffee: hashicups.Coffee{ID: 1}, Quantity: 1},
+		},
+	})
+
+	testCases := map[string]struct {
+		host          any
+		expectedError string
+	}{
+		"host": {
+			host: api.URL(),
+		},
+		"host-trailing-slash": {
+			host: api.URL() + "/",
+		},
+		"host-null": {
+			host: nil,
+		},
+		"host-mismatched": {
+			host:          "http://localhost:19090",
+			expectedError: "Mismatched HashiCups Order Identity Host",
+		},
+		"host-invalid": {
+			host:          "localhost:19090",
+			expectedError: "Invalid HashiCups Order Identity Host",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+			resp := server.importStateIdentity("hashicups_order", map[string]any{
+				"id":   "7",
+				"host": testCase.host,
+			})
+
+			if testCase.expectedError != "" {
+				server.requireError(resp.Diagnostics, testCase.expectedError)
+				return
+			}
+
+			server.requireNoErrors(resp.Diagnostics)
+
+			if len(resp.ImportedResources) != 1 {
+				t.Fatalf("expected 1 imported resource, got %d", len(resp.ImportedResources))
+			}
+
+			got := server.stringAttribute(server.resourceType("hashicups_order"), resp.ImportedResources[0].State, "id")
+			if got != "7" {
+				t.Errorf("expected id %q, got %q", "7", got)
+			}
+		})
+	}
+}
+
+func TestOrderResource_Faults(t *testing.T) {
+	testCases := map[string]struct {
+		fault         fakeAPIFault
+		expectedError string
+		expectedInErr string
+	}{
+		"server-error": {
+			fault: fakeAPIFault{
+				Method:     http.MethodGet,
+				Path:       "/orders/1",
+				StatusCode: http.StatusInternalServerError,
+				Body:       "database unavailable",
+			},
+			expectedError: "Error Reading HashiCups Order",
+			expectedInErr: "database unavailable",
+		},
+		"unauthorized-after-requests": {
+			fault: fakeAPIFault{
+				After:      1,
+				StatusCode: http.StatusUnauthorized,
+				Body:       "Unauthorized",
+			},
+			expectedError: "HashiCups API Authentication Failed",
+		},
+		"dropped-connection": {
+			fault: fakeAPIFault{
+				Method:         http.MethodGet,
+				Path:           "/orders/1",
+				DropConnection: true,
+			},
+			expectedError: "Error Reading HashiCups Order",
+			expectedInErr: "EOF",
+		},
+		"malformed-json": {
+			fault: fakeAPIFault{
+				Method:        http.MethodGet,
+				Path:          "/orders/1",
+				MalformedJSON: true,
+			},
+			expectedError: "Error Reading HashiCups Order",
+			expectedInErr: "unexpected end of JSON input",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			api := newFakeHashicupsAPI(t)
+			server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+			created := server.create("hashicups_order", orderConfig(1, 2))
+			server.requireNoErrors(created.Diagnostics)
+
+			api.inject(testCase.fault)
+
+			// The first read is served normally when the fault applies
+			// after a number of requests.
+			resp := server.read("hashicups_order", created.NewState, created.NewIdentity)
+			if testCase.fault.After > 0 {
+				server.requireNoErrors(resp.Diagnostics)
+				resp = server.read("hashicups_order", created.NewState, created.NewIdentity)
+			}
+
+			detail := server.requireError(resp.Diagnostics, testCase.expectedError)
+			if !strings.Contains(detail, testCase.expectedInErr) {
+				t.Errorf("expected detail to contain %q, got: %s", testCase.expectedInErr, detail)
+			}
+		})
+	}
+}
+
+func TestOrderResource_FaultRetried(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	api.inject(fakeAPIFault{
+		Method:     http.MethodPost,
+		Path:       "/orders",
+		Times:      1,
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{"Retry-After": []string{"0"}},
+	})
+
+	resp := server.create("hashicups_order", orderConfig(1, 2))
+	server.requireNoErrors(resp.Diagnostics)
+
+	if got := api.requestCount("POST /orders"); got != 2 {
+		t.Errorf("expected 2 requests, got %d", got)
+	}
+
+	if _, ok := api.order(1); !ok {
+		t.Error("expected order to be created once")
+	}
+}
+
+func TestOrderResource_FaultLatency(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	api.inject(fakeAPIFault{
+		Method:  http.MethodPost,
+		Path:    "/orders",
+		Latency: 100 * time.Millisecond,
+	})
+
+	start := time.Now()
+
+	resp := server.create("hashicups_order", orderConfig(1, 2))
+	server.requireNoErrors(resp.Diagnostics)
+
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected create to take at least 100ms, took %s", elapsed)
+	}
+}
+
+// The order is created, but the response is lost, so Terraform does not
+// record it in state. The next plan creates another order.
+func TestOrderResource_FaultCreateResponseLost(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	api.inject(fakeAPIFault{
+		Method:        http.MethodPost,
+		Path:          "/orders",
+		Times:         1,
+		MalformedJSON: true,
+	})
+
+	resp := server.create("hashicups_order", orderConfig(1, 2))
+	server.requireError(resp.Diagnostics, "Error creating order")
+
+	if !server.isNull(server.resourceType("hashicups_order"), resp.NewState) {
+		t.Error("expected null state after failed create")
+	}
+
+	if _, ok := api.order(1); !ok {
+		t.Error("expected order to have been created by the API")
+	}
+}
+
+func TestOrderResource_FaultSteps(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	config := fmt.Sprintf(`
+provider "hashicups" {
+  username = %q
+  password = %q
+  host     = %q
+}
+
+resource "hashicups_order" "test" {
+  items = [
+    {
+      coffee = {
+        id = 1
+      }
+      quantity = 2
+    },
+  ]
+}
+`, fakeAPIUsername, fakeAPIPassword, api.URL())
+
+	// The fake API runs in the test process, so the test does not need
+	// TF_ACC or the docker-compose stack.
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			// Refresh fails while the API returns errors
+			{
+				PreConfig: func() {
+					api.inject(fakeAPIFault{
+						Method:     http.MethodGet,
+						Path:       "/orders/1",
+						StatusCode: http.StatusInternalServerError,
+						Body:       "database unavailable",
+					})
+				},
+				Config:      config,
+				ExpectError: regexp.MustCompile(`Error Reading HashiCups Order`),
+			},
+			// Rate limited requests are retried
+			{
+				PreConfig: func() {
+					api.clearFaults()
+					api.inject(fakeAPIFault{
+						Method:     http.MethodGet,
+						Path:       "/orders/1",
+						Times:      1,
+						StatusCode: http.StatusTooManyRequests,
+						Header:     http.Header{"Retry-After": []string{"0"}},
+					})
+				},
+				Config:   config,
+				PlanOnly: true,
+			},
+			// Dropped connections fail the refresh
+			{
+				PreConfig: func() {
+					api.clearFaults()
+					api.inject(fakeAPIFault{
+						Method:         http.MethodGet,
+						Path:           "/orders/1",
+						DropConnection: true,
+					})
+				},
+				Config:      config,
+				ExpectError: regexp.MustCompile(`Error Reading HashiCups Order`),
+			},
+			// Expired session tokens are replaced by signing in again
+			{
+				PreConfig: func() {
+					api.clearFaults()
+					api.expireTokens()
+				},
+				Config:   config,
+				PlanOnly: true,
+			},
+			{
+				PreConfig: api.clearFaults,
+				Config:    config,
+				PlanOnly:  true,
+			},
+		},
+	})
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &OrderTotalFunction{}
+
+type OrderTotalFunction struct{}
+
+func NewOrderTotalFunction() function.Function {
+	return &OrderTotalFunction{}
+}
+
+func (f *OrderTotalFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "order_total"
+}
+
+func (f *OrderTotalFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Compute the total cost of an order",
+		Description: "Given the quantity and price of each order item and a tax rate, return the total cost of the order including tax.",
+
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:        "items",
+				Description: "Count of each item in the order.",
+				ElementType: types.Int64Type,
+			},
+			function.ListParameter{
+				Name:        "prices",
+				Description: "Price of each item in the order, in the same order as items.",
+				ElementType: types.Float64Type,
+			},
+			function.Float64Parameter{
+				Name:        "tax_rate",
+				Description: "Tax rate. 0.085 == 8.5%",
+				Validators: []function.Float64ParameterValidator{
+					taxRateValidator{},
+				},
+			},
+		},
+		Return: function.Float64Return{},
+	}
+}
+
+func (f *OrderTotalFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var items []types.Int64
+	var prices []types.Float64
+	var rate float64
+
+	// Read Terraform argument data into the variables
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &items, &prices, &rate))
+	if resp.Error != nil {
+		return
+	}
+
+	if len(items) != len(prices) {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("prices must have one element per item, got %d items and %d prices", len(items), len(prices)))
+		return
+	}
+
+	subtotal := new(big.Rat)
+
+	for index, item := range items {
+		price := prices[index]
+
+		if item.IsNull() || item.ValueInt64() < 0 {
+			resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0, fmt.Sprintf("items[%d] must be a quantity of zero or more", index)))
+		}
+
+		if price.IsNull() || price.ValueFloat64() < 0 {
+			resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(1, fmt.Sprintf("prices[%d] must be a price of zero or more", index)))
+		}
+
+		subtotal.Add(subtotal, new(big.Rat).Mul(decimalFromFloat64(price.ValueFloat64()), new(big.Rat).SetInt64(item.ValueInt64())))
+	}
+
+	if resp.Error != nil {
+		return
+	}
+
+	total := roundCents(addTax(subtotal, decimalFromFloat64(rate)))
+
+	// Set the result
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, total))
+}
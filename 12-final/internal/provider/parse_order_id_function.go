@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &ParseOrderIDFunction{}
+
+type ParseOrderIDFunction struct{}
+
+// parseOrderIDModel maps the parse_order_id result.
+type parseOrderIDModel struct {
+	ID       types.Int64  `tfsdk:"id"`
+	ImportID types.String `tfsdk:"import_id"`
+}
+
+func NewParseOrderIDFunction() function.Function {
+	return &ParseOrderIDFunction{}
+}
+
+func (f *ParseOrderIDFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_order_id"
+}
+
+func (f *ParseOrderIDFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Parse an order ID",
+		Description: "Given an order ID, return an object with the numeric ID and the normalized ID to import the order with. Returns an error if the ID is not a valid order ID.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "order_id",
+				Description: "Numeric identifier of the order, such as \"123\".",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"id":        types.Int64Type,
+				"import_id": types.StringType,
+			},
+		},
+	}
+}
+
+func (f *ParseOrderIDFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var orderID string
+
+	// Read Terraform argument data into the variables
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &orderID))
+	if resp.Error != nil {
+		return
+	}
+
+	id, err := parseOrderID(orderID)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	result := parseOrderIDModel{
+		ID:       types.Int64Value(int64(id)),
+		ImportID: types.StringValue(strconv.Itoa(id)),
+	}
+
+	// Set the result
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, result))
+}
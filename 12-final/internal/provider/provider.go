@@ -2,23 +2,34 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/url"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/hashicorp-demoapp/hashicups-client-go"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/list"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ provider.Provider              = &hashicupsProvider{}
-	_ provider.ProviderWithFunctions = &hashicupsProvider{}
+	_ provider.Provider                  = &hashicupsProvider{}
+	_ provider.ProviderWithFunctions     = &hashicupsProvider{}
+	_ provider.ProviderWithListResources = &hashicupsProvider{}
 )
 
 // New is a helper function to simplify provider server and testing implementation.
@@ -32,9 +43,28 @@ func New(version string) func() provider.Provider {
 
 // hashicupsProviderModel maps provider schema data to a Go type.
 type hashicupsProviderModel struct {
-	Host     types.String `tfsdk:"host"`
-	Username types.String `tfsdk:"username"`
-	Password types.String `tfsdk:"password"`
+	Host      types.String `tfsdk:"host"`
+	Username  types.String `tfsdk:"username"`
+	Password  types.String `tfsdk:"password"`
+	ProxyURL  types.String `tfsdk:"proxy_url"`
+	Headers   types.Map    `tfsdk:"headers"`
+	UserAgent types.String `tfsdk:"user_agent"`
+
+	RequestsPerSecond     types.Float64 `tfsdk:"requests_per_second"`
+	MaxConcurrentRequests types.Int64   `tfsdk:"max_concurrent_requests"`
+
+	CoffeeCacheTTL types.String `tfsdk:"coffee_cache_ttl"`
+
+	ConfigFile types.String `tfsdk:"config_file"`
+	Profile    types.String `tfsdk:"profile"`
+}
+
+// providerData is made available to resources and data sources during their
+// Configure methods. It is shared by everything the provider instance
+// configures.
+type providerData struct {
+	client  *hashicups.Client
+	coffees *coffeeCache
 }
 
 // hashicupsProvider is the provider implementation.
@@ -57,8 +87,11 @@ func (p *hashicupsProvider) Schema(_ context.Context, _ provider.SchemaRequest,
 		Description: "Interact with HashiCups.",
 		Attributes: map[string]schema.Attribute{
 			"host": schema.StringAttribute{
-				Description: "URI for HashiCups API. May also be provided via HASHICUPS_HOST environment variable.",
+				Description: "URI for HashiCups API, such as http://localhost:19090. May also be provided via HASHICUPS_HOST environment variable.",
 				Optional:    true,
+				Validators: []validator.String{
+					hostValidator{},
+				},
 			},
 			"username": schema.StringAttribute{
 				Description: "Username for HashiCups API. May also be provided via HASHICUPS_USERNAME environment variable.",
@@ -69,6 +102,43 @@ func (p *hashicupsProvider) Schema(_ context.Context, _ provider.SchemaRequest,
 				Optional:    true,
 				Sensitive:   true,
 			},
+			"config_file": schema.StringAttribute{
+				Description: "Path of a credentials file with named profiles of host, username and password settings. Settings in the file are used when neither the configuration nor the environment sets them. " +
+					"May also be provided via HASHICUPS_CONFIG_FILE environment variable. Defaults to ~/.hashicups/credentials, if it exists and can be read.",
+				Optional: true,
+			},
+			"profile": schema.StringAttribute{
+				Description: "Name of the profile to use from the credentials file. May also be provided via HASHICUPS_PROFILE environment variable. Defaults to \"default\".",
+				Optional:    true,
+			},
+			"proxy_url": schema.StringAttribute{
+				Description: "URL of a proxy to send HashiCups API requests through. Defaults to the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.",
+				Optional:    true,
+			},
+			"headers": schema.MapAttribute{
+				Description: "Additional HTTP headers to send with every HashiCups API request. " +
+					"Headers the provider sets itself, such as Authorization, Content-Type and User-Agent, cannot be replaced.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"user_agent": schema.StringAttribute{
+				Description: "Suffix to append to the User-Agent header sent with every HashiCups API request.",
+				Optional:    true,
+			},
+			"requests_per_second": schema.Float64Attribute{
+				Description: "Maximum number of HashiCups API requests to send per second, shared by all resources and data sources. " +
+					"Must be between 0.01 and 1000. Defaults to no limit.",
+				Optional: true,
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				Description: "Maximum number of HashiCups API requests in flight at any time, shared by all resources and data sources. Defaults to no limit.",
+				Optional:    true,
+			},
+			"coffee_cache_ttl": schema.StringAttribute{
+				Description: "Duration, such as \"5m\", for which the coffee catalog is cached and shared by all data sources and resources. " +
+					"When set, the coffees of hashicups_order resources are also checked against the catalog during planning. Defaults to no caching.",
+				Optional: true,
+			},
 		},
 	}
 }
@@ -84,6 +154,18 @@ func (p *hashicupsProvider) Configure(ctx context.Context, req provider.Configur
 		return
 	}
 
+	// Values which are unknown until apply, such as a host from a resource
+	// created in the same apply, defer every resource and data source of
+	// the provider rather than failing, when Terraform allows it.
+	if !req.Config.Raw.IsFullyKnown() && req.ClientCapabilities.DeferralAllowed {
+		tflog.Info(ctx, "Deferring HashiCups resources and data sources until the provider configuration is known")
+
+		resp.Deferred = &provider.Deferred{
+			Reason: provider.DeferredReasonProviderConfigUnknown,
+		}
+		return
+	}
+
 	// If practitioner provided a configuration value for any of the
 	// attributes, it must be a known value.
 
@@ -114,59 +196,194 @@ func (p *hashicupsProvider) Configure(ctx context.Context, req provider.Configur
 		)
 	}
 
+	if config.ConfigFile.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("config_file"),
+			"Unknown HashiCups Credentials File",
+			"The provider cannot create the HashiCups API client as there is an unknown configuration value for the HashiCups credentials file. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the HASHICUPS_CONFIG_FILE environment variable.",
+		)
+	}
+
+	if config.Profile.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("profile"),
+			"Unknown HashiCups Credentials Profile",
+			"The provider cannot create the HashiCups API client as there is an unknown configuration value for the HashiCups credentials profile. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the HASHICUPS_PROFILE environment variable.",
+		)
+	}
+
+	if config.ProxyURL.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("proxy_url"),
+			"Unknown HashiCups API Proxy URL",
+			"The provider cannot create the HashiCups API client as there is an unknown configuration value for the HashiCups API proxy URL. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the HTTPS_PROXY environment variable.",
+		)
+	}
+
+	if config.Headers.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("headers"),
+			"Unknown HashiCups API Headers",
+			"The provider cannot create the HashiCups API client as there is an unknown configuration value for the HashiCups API headers. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+	}
+
+	if config.UserAgent.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("user_agent"),
+			"Unknown HashiCups API User-Agent",
+			"The provider cannot create the HashiCups API client as there is an unknown configuration value for the HashiCups API User-Agent suffix. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+	}
+
+	if config.RequestsPerSecond.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("requests_per_second"),
+			"Unknown HashiCups API Request Rate",
+			"The provider cannot create the HashiCups API client as there is an unknown configuration value for the HashiCups API request rate. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+	}
+
+	if config.MaxConcurrentRequests.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("max_concurrent_requests"),
+			"Unknown HashiCups API Concurrent Request Limit",
+			"The provider cannot create the HashiCups API client as there is an unknown configuration value for the HashiCups API concurrent request limit. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+	}
+
+	if config.CoffeeCacheTTL.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("coffee_cache_ttl"),
+			"Unknown HashiCups Coffee Cache TTL",
+			"The provider cannot create the HashiCups API client as there is an unknown configuration value for the coffee cache TTL. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Default values to environment variables, but override
-	// with Terraform configuration value if set.
+	// Select the credentials file and profile. The default credentials
+	// file is optional, and is skipped when it cannot be read, but a file
+	// or profile which was asked for must exist.
 
-	host := os.Getenv("HASHICUPS_HOST")
-	username := os.Getenv("HASHICUPS_USERNAME")
-	password := os.Getenv("HASHICUPS_PASSWORD")
+	configFile := "~/" + defaultCredentialsFile
+	configFileSet := false
 
-	if !config.Host.IsNull() {
-		host = config.Host.ValueString()
+	if value := os.Getenv("HASHICUPS_CONFIG_FILE"); value != "" {
+		configFile, configFileSet = value, true
 	}
 
-	if !config.Username.IsNull() {
-		username = config.Username.ValueString()
+	if !config.ConfigFile.IsNull() {
+		configFile, configFileSet = config.ConfigFile.ValueString(), true
 	}
 
-	if !config.Password.IsNull() {
-		password = config.Password.ValueString()
+	profileName := defaultCredentialsProfile
+	profileSet := false
+
+	if value := os.Getenv("HASHICUPS_PROFILE"); value != "" {
+		profileName, profileSet = value, true
 	}
 
+	if !config.Profile.IsNull() {
+		profileName, profileSet = config.Profile.ValueString(), true
+	}
+
+	profileSource := fmt.Sprintf("%q profile of the credentials file %s", profileName, configFile)
+
+	var profile credentialsProfile
+
+	profiles, err := readCredentialsFile(configFile)
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && !configFileSet && !profileSet:
+		tflog.Debug(ctx, "No HashiCups credentials file found", map[string]any{"config_file": configFile})
+	case err != nil && !configFileSet && !profileSet:
+		tflog.Warn(ctx, "Ignoring unreadable HashiCups credentials file", map[string]any{"config_file": configFile, "error": err.Error()})
+	case err != nil:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("config_file"),
+			"Unable to Read HashiCups Credentials File",
+			"The provider cannot create the HashiCups API client as the credentials file "+configFile+" could not be read: "+err.Error()+"\n\n"+
+				"Set the config_file value in the configuration or the HASHICUPS_CONFIG_FILE environment variable to the path of a readable credentials file.",
+		)
+		return
+	default:
+		var ok bool
+
+		profile, ok = profiles[profileName]
+		if !ok && profileSet {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("profile"),
+				"Missing HashiCups Credentials Profile",
+				fmt.Sprintf("The provider cannot create the HashiCups API client as the credentials file %s has no %q profile. ", configFile, profileName)+
+					"Add the profile to the file, or set the profile value in the configuration or the HASHICUPS_PROFILE environment variable to an existing profile.",
+			)
+			return
+		}
+	}
+
+	// Settings in the Terraform configuration take precedence over the
+	// environment variables, which take precedence over the credentials
+	// file.
+
+	host := resolveSetting(config.Host, "host", "HASHICUPS_HOST", profile.Host, profileSource)
+	username := resolveSetting(config.Username, "username", "HASHICUPS_USERNAME", profile.Username, profileSource)
+	password := resolveSetting(config.Password, "password", "HASHICUPS_PASSWORD", profile.Password, profileSource)
+
 	// If any of the expected configurations are missing, return
 	// errors with provider-specific guidance.
 
-	if host == "" {
+	if host.value == "" {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("host"),
 			"Missing HashiCups API Host",
 			"The provider cannot create the HashiCups API client as there is a missing or empty value for the HashiCups API host. "+
-				"Set the host value in the configuration or use the HASHICUPS_HOST environment variable. "+
-				"If either is already set, ensure the value is not empty.",
+				"Set the host value in the configuration, use the HASHICUPS_HOST environment variable, or set host in the "+profileSource+". "+
+				host.emptyHint(),
 		)
 	}
 
-	if username == "" {
+	// The schema validators only check the host attribute, not the
+	// environment variable or the credentials file.
+	if host.value != "" {
+		if err := validateHost(host.value); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("host"),
+				"Invalid HashiCups API Host",
+				fmt.Sprintf("The host %q from the %s is not valid: %s.", host.value, host.source, err),
+			)
+		}
+
+		host.value = normalizeHost(host.value)
+	}
+
+	if username.value == "" {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("username"),
 			"Missing HashiCups API Username",
 			"The provider cannot create the HashiCups API client as there is a missing or empty value for the HashiCups API username. "+
-				"Set the username value in the configuration or use the HASHICUPS_USERNAME environment variable. "+
-				"If either is already set, ensure the value is not empty.",
+				"Set the username value in the configuration, use the HASHICUPS_USERNAME environment variable, or set username in the "+profileSource+". "+
+				username.emptyHint(),
 		)
 	}
 
-	if password == "" {
+	if password.value == "" {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("password"),
 			"Missing HashiCups API Password",
 			"The provider cannot create the HashiCups API client as there is a missing or empty value for the HashiCups API password. "+
-				"Set the password value in the configuration or use the HASHICUPS_PASSWORD environment variable. "+
-				"If either is already set, ensure the value is not empty.",
+				"Set the password value in the configuration, use the HASHICUPS_PASSWORD environment variable, or set password in the "+profileSource+". "+
+				password.emptyHint(),
 		)
 	}
 
@@ -174,33 +391,208 @@ func (p *hashicupsProvider) Configure(ctx context.Context, req provider.Configur
 		return
 	}
 
-	ctx = tflog.SetField(ctx, "hashicups_host", host)
-	ctx = tflog.SetField(ctx, "hashicups_username", username)
-	ctx = tflog.SetField(ctx, "hashicups_password", password)
-	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, "hashicups_password")
+	httpConfig := httpClientConfig{
+		UserAgent: fmt.Sprintf("Terraform/%s terraform-provider-hashicups/%s", req.TerraformVersion, p.version),
+	}
+
+	if !config.ProxyURL.IsNull() {
+		proxyURL, err := url.Parse(config.ProxyURL.ValueString())
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("proxy_url"),
+				"Invalid HashiCups API Proxy URL",
+				"The provider cannot create the HashiCups API client as the proxy URL is not valid. "+
+					"Set the proxy_url value to an absolute URL, such as http://proxy.example.com:3128.",
+			)
+			return
+		}
+
+		httpConfig.ProxyURL = proxyURL
+	}
+
+	if !config.Headers.IsNull() {
+		resp.Diagnostics.Append(config.Headers.ElementsAs(ctx, &httpConfig.Headers, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		// Replacing the headers the provider sets, such as Authorization,
+		// would break requests without any error.
+		for _, name := range slices.Sorted(maps.Keys(httpConfig.Headers)) {
+			hint, ok := reservedHeaders[http.CanonicalHeaderKey(name)]
+			if !ok {
+				continue
+			}
+
+			detail := fmt.Sprintf("The provider cannot create the HashiCups API client as the %s header is set by the provider and cannot be replaced. ", name) +
+				"Remove it from the headers value."
+			if hint != "" {
+				detail += " " + hint
+			}
+
+			resp.Diagnostics.AddAttributeError(
+				path.Root("headers").AtMapKey(name),
+				"Reserved HashiCups API Header",
+				detail,
+			)
+		}
+	}
+
+	if config.UserAgent.ValueString() != "" {
+		httpConfig.UserAgent += " " + config.UserAgent.ValueString()
+	}
+
+	if !config.RequestsPerSecond.IsNull() {
+		if rate := config.RequestsPerSecond.ValueFloat64(); rate < minRequestsPerSecond || rate > maxRequestsPerSecond {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("requests_per_second"),
+				"Invalid HashiCups API Request Rate",
+				fmt.Sprintf("The provider cannot create the HashiCups API client as the request rate must be between %g and %g, got: %g. ", float64(minRequestsPerSecond), float64(maxRequestsPerSecond), rate)+
+					"Remove the requests_per_second value from the configuration to disable rate limiting.",
+			)
+		}
+
+		httpConfig.RequestsPerSecond = config.RequestsPerSecond.ValueFloat64()
+	}
+
+	if !config.MaxConcurrentRequests.IsNull() {
+		if config.MaxConcurrentRequests.ValueInt64() <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_concurrent_requests"),
+				"Invalid HashiCups API Concurrent Request Limit",
+				"The provider cannot create the HashiCups API client as the concurrent request limit must be greater than zero. "+
+					"Remove the max_concurrent_requests value from the configuration to disable the limit.",
+			)
+		}
+
+		httpConfig.MaxConcurrentRequests = int(config.MaxConcurrentRequests.ValueInt64())
+	}
+
+	var coffeeCacheTTL time.Duration
+
+	if !config.CoffeeCacheTTL.IsNull() {
+		ttl, err := time.ParseDuration(config.CoffeeCacheTTL.ValueString())
+		if err != nil || ttl < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("coffee_cache_ttl"),
+				"Invalid HashiCups Coffee Cache TTL",
+				"The provider cannot create the HashiCups API client as the coffee cache TTL is not a valid duration. "+
+					"Set the coffee_cache_ttl value to a positive duration, such as \"5m\", or remove it to disable caching.",
+			)
+		}
+
+		coffeeCacheTTL = ttl
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The password is never logged, not even masked.
+	ctx = withLogRedaction(ctx)
+	ctx = tflog.SetField(ctx, "hashicups_host", host.value)
+	ctx = tflog.SetField(ctx, "hashicups_username", username.value)
 
-	tflog.Debug(ctx, "Creating HashiCups client")
+	httpClient := newHTTPClient(httpConfig)
 
-	// Create a new HashiCups client using the configuration values
-	client, err := hashicups.NewClient(&host, &username, &password)
+	if err := checkHealth(ctx, host.value, httpClient); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("host"),
+			"HashiCups API Unreachable",
+			fmt.Sprintf("HashiCups API unreachable at %s, read from the %s. ", host.value, host.source)+
+				"Check the host value and that the HashiCups API is running.\n\n"+
+				"Error: "+err.Error(),
+		)
+		return
+	}
+
+	tflog.Debug(ctx, "Creating HashiCups client", map[string]any{
+		"host_source":     host.source,
+		"username_source": username.source,
+		"password_source": password.source,
+	})
+
+	// Create a new HashiCups client using the configuration values,
+	// reusing the session of any other provider instance configured with
+	// the same host, credentials, headers and proxy.
+	client, err := sessions.client(ctx, host.value, username.value, password.value, httpConfig, httpClient)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diagnostic := clientErrorDiagnostic(
+			err,
 			"Unable to Create HashiCups API Client",
 			"An unexpected error occurred when creating the HashiCups API client. "+
 				"If the error is not clear, please contact the provider developers.\n\n"+
 				"HashiCups Client Error: "+err.Error(),
 		)
+
+		// Settings may come from several places, so name the source of
+		// each one.
+		resp.Diagnostics.AddError(
+			diagnostic.Summary(),
+			diagnostic.Detail()+"\n\n"+
+				fmt.Sprintf("The host was read from the %s, the username from the %s and the password from the %s.", host.source, username.source, password.source),
+		)
 		return
 	}
 
-	// Make the HashiCups client available during DataSource and Resource
-	// type Configure methods.
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	// Make the HashiCups client and coffee cache available during
+	// DataSource, Resource and ListResource type Configure methods.
+	data := &providerData{
+		client:  client,
+		coffees: newCoffeeCache(coffeeCacheTTL),
+	}
+
+	resp.DataSourceData = data
+	resp.ResourceData = data
+	resp.ListResourceData = data
 
 	tflog.Info(ctx, "Configured HashiCups client", map[string]any{"success": true})
 }
 
+// configSetting is a resolved provider setting, along with a description
+// of where it was read from for diagnostics.
+type configSetting struct {
+	value  string
+	source string
+}
+
+// resolveSetting returns the provider setting from the attribute if it is
+// set, otherwise from the environment variable, otherwise from the
+// credentials profile.
+func resolveSetting(attribute types.String, name, envName, profileValue, profileSource string) configSetting {
+	if !attribute.IsNull() {
+		return configSetting{
+			value:  attribute.ValueString(),
+			source: fmt.Sprintf("%q provider attribute", name),
+		}
+	}
+
+	if value := os.Getenv(envName); value != "" {
+		return configSetting{
+			value:  value,
+			source: envName + " environment variable",
+		}
+	}
+
+	if profileValue != "" {
+		return configSetting{
+			value:  profileValue,
+			source: profileSource,
+		}
+	}
+
+	return configSetting{}
+}
+
+// emptyHint explains why a required setting is missing.
+func (s configSetting) emptyHint() string {
+	if s.source == "" {
+		return "None of these are set."
+	}
+
+	return "The value from the " + s.source + " is empty."
+}
+
 // DataSources defines the data sources implemented in the provider.
 func (p *hashicupsProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
@@ -215,8 +607,22 @@ func (p *hashicupsProvider) Resources(_ context.Context) []func() resource.Resou
 	}
 }
 
+// ListResources defines the list resources implemented in the provider.
+func (p *hashicupsProvider) ListResources(_ context.Context) []func() list.ListResource {
+	return []func() list.ListResource{
+		NewOrderListResource,
+	}
+}
+
 func (p *hashicupsProvider) Functions(_ context.Context) []func() function.Function {
 	return []func() function.Function{
 		NewComputeTaxFunction,
+		NewComputeTaxDecimalFunction,
+		NewComputeTaxPercentFunction,
+		NewFitBudgetFunction,
+		NewImageURLFunction,
+		NewLineTotalFunction,
+		NewOrderTotalFunction,
+		NewParseOrderIDFunction,
 	}
 }
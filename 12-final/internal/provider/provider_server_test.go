@@ -0,0 +1,540 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// testProviderServer drives the provider through the same protocol calls
+// Terraform makes, so resources can be tested without the Terraform CLI.
+// Unlike Terraform, it does not validate the responses of the provider.
+type testProviderServer struct {
+	t      *testing.T
+	ctx    context.Context
+	server tfprotov6.ProviderServer
+
+	schemas         *tfprotov6.GetProviderSchemaResponse
+	identitySchemas *tfprotov6.GetResourceIdentitySchemasResponse
+}
+
+// newTestProviderServer returns a provider server configured with the
+// given provider configuration, such as {"host": api.URL()}. The
+// credentials of the fake HashiCups API are used unless overridden.
+func newTestProviderServer(t *testing.T, config map[string]any) *testProviderServer {
+	t.Helper()
+
+	return newTestProviderServerWithContext(t, context.Background(), config)
+}
+
+// newTestProviderServerWithContext is like newTestProviderServer, but every
+// protocol call is made with ctx, such as to capture logs with
+// tflogtest.RootLogger.
+func newTestProviderServerWithContext(t *testing.T, ctx context.Context, config map[string]any) *testProviderServer {
+	t.Helper()
+
+	s := newUnconfiguredTestProviderServer(t, ctx)
+
+	providerConfig := map[string]any{
+		"username": fakeAPIUsername,
+		"password": fakeAPIPassword,
+	}
+	for name, value := range config {
+		providerConfig[name] = value
+	}
+
+	s.requireNoErrors(s.configure(providerConfig).Diagnostics)
+
+	return s
+}
+
+// newUnconfiguredTestProviderServer returns a provider server which has
+// not been configured yet, so tests can configure it themselves.
+func newUnconfiguredTestProviderServer(t *testing.T, ctx context.Context) *testProviderServer {
+	t.Helper()
+
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	s := &testProviderServer{
+		t:      t,
+		ctx:    ctx,
+		server: server,
+	}
+
+	s.schemas, err = server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s.requireNoErrors(s.schemas.Diagnostics)
+
+	s.identitySchemas, err = server.GetResourceIdentitySchemas(ctx, &tfprotov6.GetResourceIdentitySchemasRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s.requireNoErrors(s.identitySchemas.Diagnostics)
+
+	return s
+}
+
+// configure configures the provider with the given provider configuration,
+// without any defaults.
+func (s *testProviderServer) configure(config map[string]any) *tfprotov6.ConfigureProviderResponse {
+	s.t.Helper()
+
+	return s.configureWithCapabilities(config, nil)
+}
+
+// configureWithCapabilities is like configure, with the given client
+// capabilities, such as to allow deferred actions.
+func (s *testProviderServer) configureWithCapabilities(config map[string]any, capabilities *tfprotov6.ConfigureProviderClientCapabilities) *tfprotov6.ConfigureProviderResponse {
+	s.t.Helper()
+
+	resp, err := s.server.ConfigureProvider(s.ctx, &tfprotov6.ConfigureProviderRequest{
+		TerraformVersion:   "1.12.0",
+		Config:             s.dynamicValue(s.schemas.Provider.ValueType(), config),
+		ClientCapabilities: capabilities,
+	})
+	if err != nil {
+		s.t.Fatalf("unexpected error: %s", err)
+	}
+
+	return resp
+}
+
+// list lists the given list resource type, with an empty list block
+// configuration, and returns every result.
+func (s *testProviderServer) list(typeName string, includeResource bool, limit int64) []tfprotov6.ListResourceResult {
+	s.t.Helper()
+
+	schema, ok := s.schemas.ListResourceSchemas[typeName]
+	if !ok {
+		s.t.Fatalf("unknown list resource type %q", typeName)
+	}
+
+	listServer, ok := s.server.(tfprotov6.ListResourceServer)
+	if !ok {
+		s.t.Fatalf("expected provider server to implement list resources, got %T", s.server)
+	}
+
+	stream, err := listServer.ListResource(s.ctx, &tfprotov6.ListResourceRequest{
+		TypeName:        typeName,
+		Config:          s.dynamicValue(schema.ValueType(), map[string]any{}),
+		IncludeResource: includeResource,
+		Limit:           limit,
+	})
+	if err != nil {
+		s.t.Fatalf("unexpected error: %s", err)
+	}
+
+	var results []tfprotov6.ListResourceResult
+	for result := range stream.Results {
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// resourceType returns the type of the state of the given resource type.
+func (s *testProviderServer) resourceType(typeName string) tftypes.Type {
+	s.t.Helper()
+
+	schema, ok := s.schemas.ResourceSchemas[typeName]
+	if !ok {
+		s.t.Fatalf("unknown resource type %q", typeName)
+	}
+
+	return schema.ValueType()
+}
+
+// dataSourceType returns the type of the state of the given data source
+// type.
+func (s *testProviderServer) dataSourceType(typeName string) tftypes.Type {
+	s.t.Helper()
+
+	schema, ok := s.schemas.DataSourceSchemas[typeName]
+	if !ok {
+		s.t.Fatalf("unknown data source type %q", typeName)
+	}
+
+	return schema.ValueType()
+}
+
+// identityType returns the type of the identity of the given resource type.
+func (s *testProviderServer) identityType(typeName string) tftypes.Type {
+	s.t.Helper()
+
+	schema, ok := s.identitySchemas.IdentitySchemas[typeName]
+	if !ok {
+		s.t.Fatalf("unknown resource identity type %q", typeName)
+	}
+
+	return schema.ValueType()
+}
+
+// create plans and applies the creation of a resource with config, and
+// returns the apply response. Errors during planning fail the test.
+func (s *testProviderServer) create(typeName string, config map[string]any) *tfprotov6.ApplyResourceChangeResponse {
+	s.t.Helper()
+
+	return s.apply(typeName, tftypes.NewValue(s.resourceType(typeName), nil), s.value(s.resourceType(typeName), config))
+}
+
+// update plans and applies the change of a resource from prior, a state
+// returned by the provider, to config, and returns the apply response.
+func (s *testProviderServer) update(typeName string, prior *tfprotov6.DynamicValue, config map[string]any) *tfprotov6.ApplyResourceChangeResponse {
+	s.t.Helper()
+
+	return s.apply(typeName, s.unmarshal(s.resourceType(typeName), prior), s.value(s.resourceType(typeName), config))
+}
+
+// delete plans and applies the deletion of a resource, and returns the
+// apply response.
+func (s *testProviderServer) delete(typeName string, prior *tfprotov6.DynamicValue) *tfprotov6.ApplyResourceChangeResponse {
+	s.t.Helper()
+
+	return s.apply(typeName, s.unmarshal(s.resourceType(typeName), prior), tftypes.NewValue(s.resourceType(typeName), nil))
+}
+
+func (s *testProviderServer) apply(typeName string, prior, config tftypes.Value) *tfprotov6.ApplyResourceChangeResponse {
+	s.t.Helper()
+
+	resourceType := s.resourceType(typeName)
+
+	planResp := s.plan(typeName, prior, config)
+	s.requireNoErrors(planResp.Diagnostics)
+
+	applyResp, err := s.server.ApplyResourceChange(s.ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:        typeName,
+		PriorState:      s.dynamicValue(resourceType, prior),
+		PlannedState:    planResp.PlannedState,
+		Config:          s.dynamicValue(resourceType, config),
+		PlannedPrivate:  planResp.PlannedPrivate,
+		PlannedIdentity: planResp.PlannedIdentity,
+	})
+	if err != nil {
+		s.t.Fatalf("unexpected error: %s", err)
+	}
+
+	return applyResp
+}
+
+// plan plans the change of the given resource from prior to config.
+func (s *testProviderServer) plan(typeName string, prior, config tftypes.Value) *tfprotov6.PlanResourceChangeResponse {
+	s.t.Helper()
+
+	resourceType := s.resourceType(typeName)
+
+	resp, err := s.server.PlanResourceChange(s.ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       s.dynamicValue(resourceType, prior),
+		ProposedNewState: s.dynamicValue(resourceType, proposedNewState(prior, config)),
+		Config:           s.dynamicValue(resourceType, config),
+	})
+	if err != nil {
+		s.t.Fatalf("unexpected error: %s", err)
+	}
+
+	return resp
+}
+
+// read refreshes state and identity, as returned by the provider, and
+// returns the read response.
+func (s *testProviderServer) read(typeName string, state *tfprotov6.DynamicValue, identity *tfprotov6.ResourceIdentityData) *tfprotov6.ReadResourceResponse {
+	s.t.Helper()
+
+	resp, err := s.server.ReadResource(s.ctx, &tfprotov6.ReadResourceRequest{
+		TypeName:        typeName,
+		CurrentState:    state,
+		CurrentIdentity: identity,
+	})
+	if err != nil {
+		s.t.Fatalf("unexpected error: %s", err)
+	}
+
+	return resp
+}
+
+// readDataSource reads a data source with config and returns the read
+// response.
+func (s *testProviderServer) readDataSource(typeName string, config map[string]any) *tfprotov6.ReadDataSourceResponse {
+	s.t.Helper()
+
+	resp, err := s.server.ReadDataSource(s.ctx, &tfprotov6.ReadDataSourceRequest{
+		TypeName: typeName,
+		Config:   s.dynamicValue(s.dataSourceType(typeName), config),
+	})
+	if err != nil {
+		s.t.Fatalf("unexpected error: %s", err)
+	}
+
+	return resp
+}
+
+// importState imports a resource by ID and returns the import response.
+func (s *testProviderServer) importState(typeName string, id string) *tfprotov6.ImportResourceStateResponse {
+	s.t.Helper()
+
+	resp, err := s.server.ImportResourceState(s.ctx, &tfprotov6.ImportResourceStateRequest{
+		TypeName: typeName,
+		ID:       id,
+	})
+	if err != nil {
+		s.t.Fatalf("unexpected error: %s", err)
+	}
+
+	return resp
+}
+
+// importStateIdentity imports a resource by identity, rather than by ID,
+// and returns the import response.
+func (s *testProviderServer) importStateIdentity(typeName string, identity map[string]any) *tfprotov6.ImportResourceStateResponse {
+	s.t.Helper()
+
+	resp, err := s.server.ImportResourceState(s.ctx, &tfprotov6.ImportResourceStateRequest{
+		TypeName: typeName,
+		Identity: &tfprotov6.ResourceIdentityData{
+			IdentityData: s.dynamicValue(s.identityType(typeName), identity),
+		},
+	})
+	if err != nil {
+		s.t.Fatalf("unexpected error: %s", err)
+	}
+
+	return resp
+}
+
+// attribute returns the value at the given path of value, a state or
+// identity returned by the provider. Steps are attribute names or list
+// indexes.
+func (s *testProviderServer) attribute(valueType tftypes.Type, value *tfprotov6.DynamicValue, steps ...any) tftypes.Value {
+	s.t.Helper()
+
+	attributePath := tftypes.NewAttributePath()
+	for _, step := range steps {
+		switch step := step.(type) {
+		case string:
+			attributePath = attributePath.WithAttributeName(step)
+		case int:
+			attributePath = attributePath.WithElementKeyInt(step)
+		default:
+			s.t.Fatalf("unsupported attribute path step %T", step)
+		}
+	}
+
+	got, _, err := tftypes.WalkAttributePath(s.unmarshal(valueType, value), attributePath)
+	if err != nil {
+		s.t.Fatalf("unexpected error walking %s: %s", attributePath, err)
+	}
+
+	v, ok := got.(tftypes.Value)
+	if !ok {
+		s.t.Fatalf("expected a value at %s, got %T", attributePath, got)
+	}
+
+	return v
+}
+
+// stringAttribute returns the string at the given path of value.
+func (s *testProviderServer) stringAttribute(valueType tftypes.Type, value *tfprotov6.DynamicValue, steps ...any) string {
+	s.t.Helper()
+
+	var got string
+	if err := s.attribute(valueType, value, steps...).As(&got); err != nil {
+		s.t.Fatalf("unexpected error: %s", err)
+	}
+
+	return got
+}
+
+// numberAttribute returns the number at the given path of value.
+func (s *testProviderServer) numberAttribute(valueType tftypes.Type, value *tfprotov6.DynamicValue, steps ...any) *big.Float {
+	s.t.Helper()
+
+	var got big.Float
+	if err := s.attribute(valueType, value, steps...).As(&got); err != nil {
+		s.t.Fatalf("unexpected error: %s", err)
+	}
+
+	return &got
+}
+
+// isNull reports whether value, a state returned by the provider, is null.
+func (s *testProviderServer) isNull(valueType tftypes.Type, value *tfprotov6.DynamicValue) bool {
+	s.t.Helper()
+
+	return value == nil || s.unmarshal(valueType, value).IsNull()
+}
+
+// requireNoErrors fails the test if diagnostics contains an error.
+func (s *testProviderServer) requireNoErrors(diagnostics []*tfprotov6.Diagnostic) {
+	s.t.Helper()
+
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == tfprotov6.DiagnosticSeverityError {
+			s.t.Fatalf("unexpected error diagnostic: %s: %s", diagnostic.Summary, diagnostic.Detail)
+		}
+	}
+}
+
+// requireError fails the test unless diagnostics contains an error with
+// the given summary, and returns its detail.
+func (s *testProviderServer) requireError(diagnostics []*tfprotov6.Diagnostic, summary string) string {
+	s.t.Helper()
+
+	var got []string
+
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity != tfprotov6.DiagnosticSeverityError {
+			continue
+		}
+
+		if diagnostic.Summary == summary {
+			return diagnostic.Detail
+		}
+
+		got = append(got, diagnostic.Summary)
+	}
+
+	s.t.Fatalf("expected error diagnostic %q, got: [%s]", summary, strings.Join(got, ", "))
+
+	return ""
+}
+
+func (s *testProviderServer) dynamicValue(valueType tftypes.Type, value any) *tfprotov6.DynamicValue {
+	s.t.Helper()
+
+	v, ok := value.(tftypes.Value)
+	if !ok {
+		v = s.value(valueType, value)
+	}
+
+	dv, err := tfprotov6.NewDynamicValue(valueType, v)
+	if err != nil {
+		s.t.Fatalf("unexpected error: %s", err)
+	}
+
+	return &dv
+}
+
+func (s *testProviderServer) unmarshal(valueType tftypes.Type, value *tfprotov6.DynamicValue) tftypes.Value {
+	s.t.Helper()
+
+	v, err := value.Unmarshal(valueType)
+	if err != nil {
+		s.t.Fatalf("unexpected error: %s", err)
+	}
+
+	return v
+}
+
+// value converts value, built from maps, slices, strings, numbers and
+// bools, to valueType. Missing object attributes are null.
+func (s *testProviderServer) value(valueType tftypes.Type, value any) tftypes.Value {
+	s.t.Helper()
+
+	if value == nil {
+		return tftypes.NewValue(valueType, nil)
+	}
+
+	switch valueType := valueType.(type) {
+	case tftypes.Object:
+		attributes, ok := value.(map[string]any)
+		if !ok {
+			s.t.Fatalf("expected map[string]any for %s, got %T", valueType, value)
+		}
+
+		values := map[string]tftypes.Value{}
+		for name, attributeType := range valueType.AttributeTypes {
+			values[name] = s.value(attributeType, attributes[name])
+		}
+
+		for name := range attributes {
+			if _, ok := valueType.AttributeTypes[name]; !ok {
+				s.t.Fatalf("unknown attribute %q", name)
+			}
+		}
+
+		return tftypes.NewValue(valueType, values)
+	case tftypes.List:
+		elements, ok := value.([]any)
+		if !ok {
+			s.t.Fatalf("expected []any for %s, got %T", valueType, value)
+		}
+
+		values := make([]tftypes.Value, 0, len(elements))
+		for _, element := range elements {
+			values = append(values, s.value(valueType.ElementType, element))
+		}
+
+		return tftypes.NewValue(valueType, values)
+	case tftypes.Map:
+		elements, ok := value.(map[string]any)
+		if !ok {
+			s.t.Fatalf("expected map[string]any for %s, got %T", valueType, value)
+		}
+
+		values := map[string]tftypes.Value{}
+		for key, element := range elements {
+			values[key] = s.value(valueType.ElementType, element)
+		}
+
+		return tftypes.NewValue(valueType, values)
+	default:
+		return tftypes.NewValue(valueType, value)
+	}
+}
+
+// proposedNewState approximates the proposed new state Terraform sends
+// when planning: the configuration, with any null attribute taken from
+// the prior state. This is only correct for schemas in which every
+// attribute which may be null in the configuration is computed, as in
+// hashicups_order.
+func proposedNewState(prior, config tftypes.Value) tftypes.Value {
+	if prior.IsNull() || config.IsNull() || !config.IsKnown() {
+		return config
+	}
+
+	switch configType := config.Type().(type) {
+	case tftypes.Object:
+		var priorAttributes, configAttributes map[string]tftypes.Value
+		_ = prior.As(&priorAttributes)
+		_ = config.As(&configAttributes)
+
+		values := map[string]tftypes.Value{}
+		for name, value := range configAttributes {
+			if value.IsNull() {
+				values[name] = priorAttributes[name]
+				continue
+			}
+
+			values[name] = proposedNewState(priorAttributes[name], value)
+		}
+
+		return tftypes.NewValue(configType, values)
+	case tftypes.List:
+		var priorElements, configElements []tftypes.Value
+		_ = prior.As(&priorElements)
+		_ = config.As(&configElements)
+
+		values := make([]tftypes.Value, 0, len(configElements))
+		for index, value := range configElements {
+			if index < len(priorElements) {
+				value = proposedNewState(priorElements[index], value)
+			}
+
+			values = append(values, value)
+		}
+
+		return tftypes.NewValue(configType, values)
+	default:
+		return config
+	}
+}
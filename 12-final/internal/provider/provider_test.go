@@ -1,8 +1,17 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp-demoapp/hashicups-client-go"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
 const (
@@ -28,3 +37,410 @@ var (
 		"hashicups": providerserver.NewProtocol6WithError(New("test")()),
 	}
 )
+
+// TestMain runs the tests, or the sweepers when the -sweep flag is set.
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+// sharedClient returns a HashiCups client configured like providerConfig,
+// for acceptance tests and sweepers.
+func sharedClient() (*hashicups.Client, error) {
+	return newHashicupsClient(context.Background(), "http://localhost:19090", "education", "test123", newHTTPClient(httpClientConfig{}))
+}
+
+// testAccClient returns a HashiCups client configured like providerConfig,
+// so acceptance tests can change orders outside of Terraform.
+func testAccClient(t *testing.T) *hashicups.Client {
+	t.Helper()
+
+	client, err := sharedClient()
+	if err != nil {
+		t.Fatalf("error creating HashiCups client: %s", err)
+	}
+
+	return client
+}
+
+// isolateProviderEnvironment clears the HASHICUPS_ environment variables
+// and the home directory for the rest of the test, so neither the
+// environment nor the credentials file of the user running the tests are
+// used. It returns the path of the default credentials file.
+func isolateProviderEnvironment(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	for _, name := range []string{"HASHICUPS_HOST", "HASHICUPS_USERNAME", "HASHICUPS_PASSWORD", "HASHICUPS_CONFIG_FILE", "HASHICUPS_PROFILE"} {
+		t.Setenv(name, "")
+	}
+
+	return filepath.Join(home, defaultCredentialsFile)
+}
+
+func TestProvider_ConfigureCredentialsFile(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+
+	// profile returns a credentials file profile for the fake API.
+	profile := func(name, password string) string {
+		return fmt.Sprintf("[%s]\nhost = %s\nusername = %s\npassword = %s\n", name, api.URL(), fakeAPIUsername, password)
+	}
+
+	t.Run("default file and profile", func(t *testing.T) {
+		credentialsFile := isolateProviderEnvironment(t)
+		writeCredentialsFile(t, credentialsFile, profile("default", fakeAPIPassword))
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		server.requireNoErrors(server.configure(map[string]any{}).Diagnostics)
+	})
+
+	t.Run("named profile", func(t *testing.T) {
+		credentialsFile := isolateProviderEnvironment(t)
+		writeCredentialsFile(t, credentialsFile, profile("default", "wrong")+profile("test", fakeAPIPassword))
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		server.requireNoErrors(server.configure(map[string]any{"profile": "test"}).Diagnostics)
+	})
+
+	t.Run("profile from environment", func(t *testing.T) {
+		credentialsFile := isolateProviderEnvironment(t)
+		writeCredentialsFile(t, credentialsFile, profile("default", "wrong")+profile("test", fakeAPIPassword))
+		t.Setenv("HASHICUPS_PROFILE", "test")
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		server.requireNoErrors(server.configure(map[string]any{}).Diagnostics)
+	})
+
+	t.Run("config file", func(t *testing.T) {
+		isolateProviderEnvironment(t)
+
+		credentialsFile := filepath.Join(t.TempDir(), "hashicups.ini")
+		writeCredentialsFile(t, credentialsFile, profile("default", fakeAPIPassword))
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		server.requireNoErrors(server.configure(map[string]any{"config_file": credentialsFile}).Diagnostics)
+	})
+
+	t.Run("config file from environment", func(t *testing.T) {
+		isolateProviderEnvironment(t)
+
+		credentialsFile := filepath.Join(t.TempDir(), "hashicups.ini")
+		writeCredentialsFile(t, credentialsFile, profile("default", fakeAPIPassword))
+		t.Setenv("HASHICUPS_CONFIG_FILE", credentialsFile)
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		server.requireNoErrors(server.configure(map[string]any{}).Diagnostics)
+	})
+
+	t.Run("environment overrides file", func(t *testing.T) {
+		credentialsFile := isolateProviderEnvironment(t)
+		writeCredentialsFile(t, credentialsFile, profile("default", "wrong"))
+		t.Setenv("HASHICUPS_PASSWORD", fakeAPIPassword)
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		server.requireNoErrors(server.configure(map[string]any{}).Diagnostics)
+	})
+
+	t.Run("attribute overrides environment", func(t *testing.T) {
+		credentialsFile := isolateProviderEnvironment(t)
+		writeCredentialsFile(t, credentialsFile, profile("default", "wrong"))
+		t.Setenv("HASHICUPS_PASSWORD", "wrong")
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		server.requireNoErrors(server.configure(map[string]any{"password": fakeAPIPassword}).Diagnostics)
+	})
+
+	t.Run("sign in error names sources", func(t *testing.T) {
+		credentialsFile := isolateProviderEnvironment(t)
+		writeCredentialsFile(t, credentialsFile, profile("default", "wrong"))
+		t.Setenv("HASHICUPS_USERNAME", fakeAPIUsername)
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		resp := server.configure(map[string]any{"host": api.URL()})
+
+		detail := server.requireError(resp.Diagnostics, "HashiCups API Authentication Failed")
+		expected := fmt.Sprintf("The host was read from the \"host\" provider attribute, the username from the HASHICUPS_USERNAME environment variable "+
+			"and the password from the \"default\" profile of the credentials file ~/%s.", defaultCredentialsFile)
+		if !strings.Contains(detail, expected) {
+			t.Errorf("expected detail to contain %q, got: %s", expected, detail)
+		}
+	})
+
+	t.Run("missing default file", func(t *testing.T) {
+		isolateProviderEnvironment(t)
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		resp := server.configure(map[string]any{"host": api.URL(), "username": fakeAPIUsername})
+
+		detail := server.requireError(resp.Diagnostics, "Missing HashiCups API Password")
+		if !strings.Contains(detail, "None of these are set.") {
+			t.Errorf("expected detail to explain no value is set, got: %s", detail)
+		}
+	})
+
+	t.Run("empty attribute", func(t *testing.T) {
+		credentialsFile := isolateProviderEnvironment(t)
+		writeCredentialsFile(t, credentialsFile, profile("default", fakeAPIPassword))
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		resp := server.configure(map[string]any{"username": ""})
+
+		detail := server.requireError(resp.Diagnostics, "Missing HashiCups API Username")
+		if !strings.Contains(detail, `The value from the "username" provider attribute is empty.`) {
+			t.Errorf("expected detail to name the empty attribute, got: %s", detail)
+		}
+	})
+
+	t.Run("missing config file", func(t *testing.T) {
+		isolateProviderEnvironment(t)
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		resp := server.configure(map[string]any{"config_file": filepath.Join(t.TempDir(), "missing")})
+
+		server.requireError(resp.Diagnostics, "Unable to Read HashiCups Credentials File")
+	})
+
+	t.Run("missing default file with profile", func(t *testing.T) {
+		isolateProviderEnvironment(t)
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		resp := server.configure(map[string]any{"profile": "test"})
+
+		server.requireError(resp.Diagnostics, "Unable to Read HashiCups Credentials File")
+	})
+
+	t.Run("missing profile", func(t *testing.T) {
+		credentialsFile := isolateProviderEnvironment(t)
+		writeCredentialsFile(t, credentialsFile, profile("default", fakeAPIPassword))
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		resp := server.configure(map[string]any{"profile": "staging"})
+
+		detail := server.requireError(resp.Diagnostics, "Missing HashiCups Credentials Profile")
+		if !strings.Contains(detail, `has no "staging" profile`) {
+			t.Errorf("expected detail to name the profile, got: %s", detail)
+		}
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		isolateProviderEnvironment(t)
+
+		credentialsFile := filepath.Join(t.TempDir(), "hashicups.ini")
+		writeCredentialsFile(t, credentialsFile, "host = "+api.URL()+"\n")
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		resp := server.configure(map[string]any{"config_file": credentialsFile})
+
+		detail := server.requireError(resp.Diagnostics, "Unable to Read HashiCups Credentials File")
+		if !strings.Contains(detail, "line 1: setting must follow a profile header") {
+			t.Errorf("expected detail to contain the parse error, got: %s", detail)
+		}
+	})
+
+	t.Run("invalid default file", func(t *testing.T) {
+		credentialsFile := isolateProviderEnvironment(t)
+		writeCredentialsFile(t, credentialsFile, "host = "+api.URL()+"\n")
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		server.requireNoErrors(server.configure(map[string]any{
+			"host":     api.URL(),
+			"username": fakeAPIUsername,
+			"password": fakeAPIPassword,
+		}).Diagnostics)
+	})
+
+	t.Run("home unset", func(t *testing.T) {
+		isolateProviderEnvironment(t)
+		t.Setenv("HOME", "")
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		server.requireNoErrors(server.configure(map[string]any{
+			"host":     api.URL(),
+			"username": fakeAPIUsername,
+			"password": fakeAPIPassword,
+		}).Diagnostics)
+	})
+
+	t.Run("home unset with profile", func(t *testing.T) {
+		isolateProviderEnvironment(t)
+		t.Setenv("HOME", "")
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		resp := server.configure(map[string]any{"profile": "test"})
+
+		detail := server.requireError(resp.Diagnostics, "Unable to Read HashiCups Credentials File")
+		if !strings.Contains(detail, `cannot expand "~"`) {
+			t.Errorf("expected detail to explain the home directory is unknown, got: %s", detail)
+		}
+	})
+}
+
+func TestProvider_ConfigureHost(t *testing.T) {
+	t.Run("trailing slash", func(t *testing.T) {
+		api := newFakeHashicupsAPI(t)
+		server := newTestProviderServer(t, map[string]any{"host": api.URL() + "/"})
+
+		created := server.create("hashicups_order", orderConfig(1, 2))
+		server.requireNoErrors(created.Diagnostics)
+
+		if got := api.requestCount("POST /orders"); got != 1 {
+			t.Errorf("expected request to /orders, got %d", got)
+		}
+
+		if got := server.stringAttribute(server.identityType("hashicups_order"), created.NewIdentity.IdentityData, "host"); got != api.URL() {
+			t.Errorf("expected identity host %q, got %q", api.URL(), got)
+		}
+	})
+
+	t.Run("health check", func(t *testing.T) {
+		api := newFakeHashicupsAPI(t)
+		newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+		if got := api.requestCount("GET /health"); got != 1 {
+			t.Errorf("expected 1 health check, got %d", got)
+		}
+	})
+
+	t.Run("invalid host from environment", func(t *testing.T) {
+		isolateProviderEnvironment(t)
+		t.Setenv("HASHICUPS_HOST", "localhost:19090")
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		resp := server.configure(map[string]any{"username": fakeAPIUsername, "password": fakeAPIPassword})
+
+		detail := server.requireError(resp.Diagnostics, "Invalid HashiCups API Host")
+		if !strings.Contains(detail, `The host "localhost:19090" from the HASHICUPS_HOST environment variable is not valid`) {
+			t.Errorf("expected detail to name the host and its source, got: %s", detail)
+		}
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		api := newFakeHashicupsAPI(t)
+		host := api.URL()
+		api.server.Close()
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		resp := server.configure(map[string]any{"host": host, "username": fakeAPIUsername, "password": fakeAPIPassword})
+
+		detail := server.requireError(resp.Diagnostics, "HashiCups API Unreachable")
+		if !strings.Contains(detail, "HashiCups API unreachable at "+host+", read from the \"host\" provider attribute.") {
+			t.Errorf("expected detail to name the host, got: %s", detail)
+		}
+	})
+}
+
+func TestProvider_ConfigureHeaders(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+
+	t.Run("custom", func(t *testing.T) {
+		newTestProviderServer(t, map[string]any{
+			"host":    api.URL(),
+			"headers": map[string]any{"X-Tenant": "cafe"},
+		})
+	})
+
+	t.Run("reserved", func(t *testing.T) {
+		isolateProviderEnvironment(t)
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		resp := server.configure(map[string]any{
+			"host":     api.URL(),
+			"username": fakeAPIUsername,
+			"password": fakeAPIPassword,
+			"headers":  map[string]any{"authorization": "token", "X-Tenant": "cafe"},
+		})
+
+		detail := server.requireError(resp.Diagnostics, "Reserved HashiCups API Header")
+		if !strings.Contains(detail, "the authorization header is set by the provider") || !strings.Contains(detail, "Set the username and password values instead.") {
+			t.Errorf("expected detail to name the header and the alternative, got: %s", detail)
+		}
+	})
+}
+
+func TestProvider_ConfigureRequestsPerSecond(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+
+	for _, rate := range []float64{0, 1e-12, 1e12} {
+		t.Run(fmt.Sprint(rate), func(t *testing.T) {
+			isolateProviderEnvironment(t)
+
+			server := newUnconfiguredTestProviderServer(t, context.Background())
+			resp := server.configure(map[string]any{
+				"host":                api.URL(),
+				"username":            fakeAPIUsername,
+				"password":            fakeAPIPassword,
+				"requests_per_second": rate,
+			})
+
+			detail := server.requireError(resp.Diagnostics, "Invalid HashiCups API Request Rate")
+			if !strings.Contains(detail, "must be between 0.01 and 1000") {
+				t.Errorf("expected detail to give the valid range, got: %s", detail)
+			}
+		})
+	}
+}
+
+func TestProvider_ConfigureDeferred(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+
+	// The host is unknown, such as when it comes from a resource which has
+	// not been created yet.
+	config := map[string]any{
+		"host":     tftypes.UnknownValue,
+		"username": fakeAPIUsername,
+		"password": fakeAPIPassword,
+	}
+
+	t.Run("deferral allowed", func(t *testing.T) {
+		existing := newTestProviderServer(t, map[string]any{"host": api.URL()})
+		created := existing.create("hashicups_order", orderConfig(1, 2))
+		existing.requireNoErrors(created.Diagnostics)
+
+		requests := api.requestCount("POST /signin")
+
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		resp := server.configureWithCapabilities(config, &tfprotov6.ConfigureProviderClientCapabilities{
+			DeferralAllowed: true,
+		})
+		server.requireNoErrors(resp.Diagnostics)
+
+		if got := api.requestCount("POST /signin"); got != requests {
+			t.Errorf("expected deferred provider not to sign in, got %d new sign-ins", got-requests)
+		}
+
+		stateType := server.resourceType("hashicups_order")
+
+		planned := server.plan("hashicups_order", tftypes.NewValue(stateType, nil), server.value(stateType, orderConfig(1, 2)))
+		server.requireNoErrors(planned.Diagnostics)
+
+		if planned.Deferred == nil || planned.Deferred.Reason != tfprotov6.DeferredReasonProviderConfigUnknown {
+			t.Errorf("expected order plan to be deferred, got: %v", planned.Deferred)
+		}
+
+		read := server.read("hashicups_order", created.NewState, created.NewIdentity)
+		server.requireNoErrors(read.Diagnostics)
+
+		if read.Deferred == nil || read.Deferred.Reason != tfprotov6.DeferredReasonProviderConfigUnknown {
+			t.Errorf("expected order read to be deferred, got: %v", read.Deferred)
+		}
+
+		coffees := server.readDataSource("hashicups_coffees", map[string]any{})
+		server.requireNoErrors(coffees.Diagnostics)
+
+		if coffees.Deferred == nil || coffees.Deferred.Reason != tfprotov6.DeferredReasonProviderConfigUnknown {
+			t.Errorf("expected coffees read to be deferred, got: %v", coffees.Deferred)
+		}
+
+		if got := api.requestCount("GET /orders/1"); got != 0 {
+			t.Errorf("expected deferred operations not to call the API, got %d order requests", got)
+		}
+	})
+
+	t.Run("deferral not allowed", func(t *testing.T) {
+		server := newUnconfiguredTestProviderServer(t, context.Background())
+		resp := server.configure(config)
+
+		server.requireError(resp.Diagnostics, "Unknown HashiCups API Host")
+	})
+}
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// The provider never attaches secrets, such as the password or the API
+// token, to the log context or to log messages. Masking sensitive fields
+// only guards against mistakes.
+
+// redactedValue replaces secrets in logs, like tflog masking does.
+const redactedValue = "***"
+
+// sensitiveLogFields are the log fields whose values are always masked.
+var sensitiveLogFields = []string{
+	"authorization",
+	"hashicups_password",
+	"hashicups_token",
+}
+
+// sensitiveHeaders are the HTTP headers whose values are never logged.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Cookie":              true,
+	"Proxy-Authorization": true,
+	"Set-Cookie":          true,
+}
+
+// sensitiveHeaderPattern matches the names of custom headers which likely
+// carry secrets, such as X-Api-Key.
+var sensitiveHeaderPattern = regexp.MustCompile(`(?i)auth|token|secret|key|password|session`)
+
+// withLogRedaction returns ctx with sensitive fields masked in the root
+// logger.
+func withLogRedaction(ctx context.Context) context.Context {
+	return tflog.MaskFieldValuesWithFieldKeys(ctx, sensitiveLogFields...)
+}
+
+// withSubsystemLogRedaction returns ctx with sensitive fields masked in the
+// given subsystem. Root logger masking does not apply to subsystems.
+func withSubsystemLogRedaction(ctx context.Context, subsystem string) context.Context {
+	return tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, subsystem, sensitiveLogFields...)
+}
+
+// redactHeaders returns header as a log field value, with the values of
+// sensitive headers redacted.
+func redactHeaders(header http.Header) map[string]any {
+	redacted := make(map[string]any, len(header))
+
+	for name, values := range header {
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] || sensitiveHeaderPattern.MatchString(name) {
+			redacted[name] = redactedValue
+			continue
+		}
+
+		if len(values) == 1 {
+			redacted[name] = values[0]
+			continue
+		}
+
+		redacted[name] = values
+	}
+
+	return redacted
+}
@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{
+		"Authorization": []string{"token"},
+		"Cookie":        []string{"session=abc"},
+		"X-Api-Key":     []string{"key"},
+		"X-Auth-Token":  []string{"token"},
+		"Accept":        []string{"application/json"},
+		"X-Tenant":      []string{"cafe", "bar"},
+	}
+
+	expected := map[string]any{
+		"Authorization": redactedValue,
+		"Cookie":        redactedValue,
+		"X-Api-Key":     redactedValue,
+		"X-Auth-Token":  redactedValue,
+		"Accept":        "application/json",
+		"X-Tenant":      []string{"cafe", "bar"},
+	}
+
+	got := redactHeaders(header)
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestProviderLogs_NoSecrets(t *testing.T) {
+	var output bytes.Buffer
+
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServerWithContext(t, tflogtest.RootLogger(context.Background(), &output), map[string]any{
+		"host": api.URL(),
+		"headers": map[string]any{
+			"X-Api-Key": "custom-secret",
+		},
+	})
+
+	created := server.create("hashicups_order", orderConfig(1, 2))
+	server.requireNoErrors(created.Diagnostics)
+
+	read := server.read("hashicups_order", created.NewState, created.NewIdentity)
+	server.requireNoErrors(read.Diagnostics)
+
+	deleted := server.delete("hashicups_order", read.NewState)
+	server.requireNoErrors(deleted.Diagnostics)
+
+	logs := output.String()
+
+	for name, secret := range map[string]string{
+		"password":      fakeAPIPassword,
+		"token":         fakeAPIToken,
+		"custom header": "custom-secret",
+	} {
+		if strings.Contains(logs, secret) {
+			t.Errorf("expected logs not to contain the %s", name)
+		}
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Verify the headers were logged, so the test does not pass only
+	// because nothing was.
+	var headersLogged bool
+
+	for _, entry := range entries {
+		if entry["@message"] != "HashiCups API request headers" || entry["http_path"] != "/orders" {
+			continue
+		}
+
+		headers, _ := entry["http_request_headers"].(map[string]any)
+
+		if headers["Authorization"] != redactedValue || headers["X-Api-Key"] != redactedValue {
+			t.Errorf("expected sensitive headers to be redacted, got: %v", headers)
+		}
+
+		headersLogged = true
+	}
+
+	if !headersLogged {
+		t.Error("expected request headers to be logged")
+	}
+}
@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// defaultRetryBudget bounds the total time spent waiting for rate limits
+	// to reset when the operation context has no deadline.
+	defaultRetryBudget = 2 * time.Minute
+
+	// defaultRetryWait is the initial wait when a rate limited response has
+	// no usable Retry-After header. It doubles with each attempt.
+	defaultRetryWait = 1 * time.Second
+)
+
+// rateLimitedError is returned when the HashiCups API keeps rate limiting a
+// request after the retry budget has been exhausted.
+type rateLimitedError struct {
+	// StatusCode is the status of the last response, either 429 or 503.
+	StatusCode int
+
+	// RetryAfter is how long the API asked the provider to wait before
+	// sending the request again, or zero if it did not say.
+	RetryAfter time.Duration
+}
+
+// Error implements error.
+func (e *rateLimitedError) Error() string {
+	if e.RetryAfter == 0 {
+		return fmt.Sprintf("HashiCups API rate limited the request (status %d)", e.StatusCode)
+	}
+
+	return fmt.Sprintf("HashiCups API rate limited the request (status %d), retry after %s", e.StatusCode, e.RetryAfter)
+}
+
+// retryTransport retries requests which the HashiCups API rejected with 429
+// Too Many Requests, or with 503 Service Unavailable and a Retry-After
+// header, for as long as the operation allows. Once the budget is exhausted
+// the last rate limited response is returned.
+type retryTransport struct {
+	budget time.Duration
+	next   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	deadline := time.Now().Add(t.budget)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isRateLimited(resp) {
+			return resp, nil
+		}
+
+		// A request body which cannot be replayed cannot be retried.
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+
+		wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok {
+			wait = defaultRetryWait << (attempt - 1)
+		}
+
+		if time.Now().Add(wait).After(deadline) {
+			return resp, nil
+		}
+
+		// Drain the body so the connection can be reused.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		tflog.SubsystemDebug(ctx, apiLogSubsystem, "HashiCups API rate limited request, retrying", map[string]any{
+			"attempt":     attempt,
+			"retry_after": wait.String(),
+			"http_method": req.Method,
+			"http_path":   req.URL.Path,
+			"http_status": resp.StatusCode,
+		})
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+
+		req = req.Clone(ctx)
+
+		if req.GetBody != nil {
+			req.Body, err = req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// isRateLimited reports whether resp asks the provider to retry later.
+func isRateLimited(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		return resp.Header.Get("Retry-After") != ""
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter parses a Retry-After header value, which is either a
+// number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	if wait := date.Sub(now); wait > 0 {
+		return wait, true
+	}
+
+	return 0, true
+}
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp-demoapp/hashicups-client-go"
+)
+
+func TestRetryTransport_RetryAfter(t *testing.T) {
+	var requests int
+	var bodies []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := newHTTPClient(httpClientConfig{})
+
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`[{"quantity":2}]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+
+	for _, body := range bodies {
+		if body != `[{"quantity":2}]` {
+			t.Errorf("expected request body to be replayed, got %q", body)
+		}
+	}
+}
+
+func TestRetryTransport_BudgetExhausted(t *testing.T) {
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := &hashicups.Client{
+		HostURL:    server.URL,
+		HTTPClient: newHTTPClient(httpClientConfig{}),
+	}
+
+	_, err := clientWithContext(context.Background(), client).GetCoffees()
+
+	var rateLimitedErr *rateLimitedError
+	if !errors.As(err, &rateLimitedErr) {
+		t.Fatalf("expected rate limited error, got: %v", err)
+	}
+
+	if rateLimitedErr.RetryAfter != time.Hour {
+		t.Errorf("expected retry after %s, got %s", time.Hour, rateLimitedErr.RetryAfter)
+	}
+
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		"empty": {
+			value: "",
+		},
+		"seconds": {
+			value:    "30",
+			expected: 30 * time.Second,
+			ok:       true,
+		},
+		"negative-seconds": {
+			value: "-1",
+		},
+		"http-date": {
+			value:    "Sat, 01 Jun 2024 12:01:00 GMT",
+			expected: time.Minute,
+			ok:       true,
+		},
+		"http-date-past": {
+			value:    "Sat, 01 Jun 2024 11:00:00 GMT",
+			expected: 0,
+			ok:       true,
+		},
+		"invalid": {
+			value: "soon",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, ok := parseRetryAfter(testCase.value, now)
+
+			if ok != testCase.ok {
+				t.Errorf("expected ok %t, got %t", testCase.ok, ok)
+			}
+
+			if got != testCase.expected {
+				t.Errorf("expected %s, got %s", testCase.expected, got)
+			}
+		})
+	}
+}
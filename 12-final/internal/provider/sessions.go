@@ -0,0 +1,228 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hashicorp-demoapp/hashicups-client-go"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// sessions is the session registry of the provider process. Every provider
+// instance configured with the same host, credentials, headers and proxy,
+// such as aliased providers for the same HashiCups instance, shares one
+// session rather than signing in again.
+var sessions = newSessionRegistry()
+
+// transports holds the HTTP transport, and so the connection pool, of each
+// proxy the provider process sends requests through.
+var transports = newTransportRegistry()
+
+// sessionKey identifies a HashiCups API session.
+type sessionKey struct {
+	host     string
+	username string
+
+	// passwordHash distinguishes sessions of the same user with different
+	// passwords, without keeping the password in the registry.
+	passwordHash [sha256.Size]byte
+
+	// routeHash distinguishes sessions which send requests with different
+	// headers or through different proxies, as a gateway in front of the
+	// API may route them to different backends.
+	routeHash [sha256.Size]byte
+}
+
+// newSessionKey returns the key of the session for the given host and
+// credentials, sending requests as configured by httpConfig.
+func newSessionKey(host, username, password string, httpConfig httpClientConfig) sessionKey {
+	route := sha256.New()
+
+	if httpConfig.ProxyURL != nil {
+		fmt.Fprintf(route, "proxy %q\n", httpConfig.ProxyURL.String())
+	}
+
+	// Header names are case-insensitive, and map iteration order is
+	// random, so the headers are hashed by canonical name in sorted order.
+	headers := make(map[string]string, len(httpConfig.Headers))
+	for name, value := range httpConfig.Headers {
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(headers)) {
+		fmt.Fprintf(route, "header %q %q\n", name, headers[name])
+	}
+
+	key := sessionKey{
+		host:         host,
+		username:     username,
+		passwordHash: sha256.Sum256([]byte(password)),
+	}
+	route.Sum(key.routeHash[:0])
+
+	return key
+}
+
+// session is a signed in HashiCups API session.
+type session struct {
+	// mu is held while signing in, so concurrent provider instances and
+	// requests result in a single sign-in.
+	mu    sync.Mutex
+	token string
+}
+
+// currentToken returns the token of the session, waiting for any sign-in
+// in progress.
+func (s *session) currentToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.token
+}
+
+// refresh replaces staleToken with a token from signIn, and returns the new
+// token. When another request has already replaced staleToken, its token is
+// returned without signing in again.
+func (s *session) refresh(staleToken string, signIn func() (string, error)) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.token != staleToken {
+		return s.token, nil
+	}
+
+	token, err := signIn()
+	if err != nil {
+		return "", err
+	}
+
+	s.token = token
+
+	return token, nil
+}
+
+// sessionRegistry shares HashiCups API sessions between provider instances.
+type sessionRegistry struct {
+	mu       sync.Mutex
+	sessions map[sessionKey]*session
+}
+
+func newSessionRegistry() *sessionRegistry {
+	return &sessionRegistry{
+		sessions: make(map[sessionKey]*session),
+	}
+}
+
+// client returns a HashiCups client for the given host and credentials
+// which sends requests with httpClient, created from httpConfig. It reuses
+// the token of an existing session with the same headers and proxy, and
+// otherwise signs in. Failed sign-ins are not remembered. The client signs
+// in again when the API rejects the token of the session.
+func (r *sessionRegistry) client(ctx context.Context, host, username, password string, httpConfig httpClientConfig, httpClient *http.Client) (*hashicups.Client, error) {
+	s := r.session(newSessionKey(host, username, password, httpConfig))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	authHTTPClient := *httpClient
+	authHTTPClient.Transport = &authTransport{
+		session:  s,
+		host:     host,
+		username: username,
+		password: password,
+		next:     httpClient.Transport,
+	}
+
+	if s.token != "" {
+		tflog.Debug(ctx, "Reusing HashiCups API session")
+
+		return &hashicups.Client{
+			HostURL:    host,
+			HTTPClient: &authHTTPClient,
+			Token:      s.token,
+			Auth: hashicups.AuthStruct{
+				Username: username,
+				Password: password,
+			},
+		}, nil
+	}
+
+	client, err := newHashicupsClient(ctx, host, username, password, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	s.token = client.Token
+	client.HTTPClient = &authHTTPClient
+
+	tflog.Debug(ctx, "Signed in to HashiCups API")
+
+	return client, nil
+}
+
+// session returns the session for key, creating it if needed.
+func (r *sessionRegistry) session(key sessionKey) *session {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.sessions[key]
+	if !ok {
+		s = &session{}
+		r.sessions[key] = s
+	}
+
+	return s
+}
+
+// transportRegistry shares HTTP transports between provider instances.
+type transportRegistry struct {
+	mu         sync.Mutex
+	transports map[string]*http.Transport
+}
+
+func newTransportRegistry() *transportRegistry {
+	return &transportRegistry{
+		transports: make(map[string]*http.Transport),
+	}
+}
+
+// transport returns the shared transport for requests sent through
+// proxyURL. When proxyURL is nil, the standard HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY environment variables are honoured.
+func (r *transportRegistry) transport(proxyURL *url.URL) *http.Transport {
+	var key string
+	if proxyURL != nil {
+		key = proxyURL.String()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if transport, ok := r.transports[key]; ok {
+		return transport
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	if proxyURL != nil {
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	r.transports[key] = transport
+
+	return transport
+}
@@ -0,0 +1,227 @@
+package provider
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// isolateSessions replaces the session and transport registries of the
+// provider process with empty ones for the rest of the test, so sessions
+// and connections are not shared with other tests.
+func isolateSessions(t *testing.T) {
+	t.Helper()
+
+	originalSessions, originalTransports := sessions, transports
+	sessions, transports = newSessionRegistry(), newTransportRegistry()
+
+	t.Cleanup(func() {
+		for _, transport := range transports.transports {
+			transport.CloseIdleConnections()
+		}
+
+		sessions, transports = originalSessions, originalTransports
+	})
+}
+
+func TestIsolateSessions(t *testing.T) {
+	original := sessions
+
+	t.Run("isolated", func(t *testing.T) {
+		isolateSessions(t)
+
+		if sessions == original {
+			t.Error("expected the session registry to be replaced")
+		}
+	})
+
+	if sessions != original {
+		t.Error("expected the session registry to be restored")
+	}
+}
+
+func TestSessionRegistry_Client(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	registry := newSessionRegistry()
+
+	first, err := registry.client(context.Background(), api.URL(), fakeAPIUsername, fakeAPIPassword, httpClientConfig{}, newHTTPClient(httpClientConfig{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	second, err := registry.client(context.Background(), api.URL(), fakeAPIUsername, fakeAPIPassword, httpClientConfig{}, newHTTPClient(httpClientConfig{
+		UserAgent: "secondary",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got := api.requestCount("POST /signin"); got != 1 {
+		t.Errorf("expected 1 sign-in, got %d", got)
+	}
+
+	if first.Token == "" || first.Token != second.Token {
+		t.Errorf("expected both clients to use the same token, got %q and %q", first.Token, second.Token)
+	}
+
+	if first == second || first.HTTPClient == second.HTTPClient {
+		t.Error("expected each client to keep its own HTTP client")
+	}
+
+	if _, err := clientWithContext(context.Background(), second).GetOrder("1"); err == nil {
+		t.Error("expected error reading missing order")
+	}
+
+	if got := api.requestCount("GET /orders/1"); got != 1 {
+		t.Errorf("expected shared session to be usable, got %d order requests", got)
+	}
+}
+
+func TestSessionRegistry_ClientRoute(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+
+	proxyURL, err := url.Parse("http://proxy.example.com:3128")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	testCases := map[string]struct {
+		first, second httpClientConfig
+		expectShared  bool
+	}{
+		"same headers": {
+			first:        httpClientConfig{Headers: map[string]string{"X-Team": "coffee", "X-Env": "test"}},
+			second:       httpClientConfig{Headers: map[string]string{"x-env": "test", "x-team": "coffee"}},
+			expectShared: true,
+		},
+		"different headers": {
+			first:  httpClientConfig{Headers: map[string]string{"X-Team": "coffee"}},
+			second: httpClientConfig{Headers: map[string]string{"X-Team": "tea"}},
+		},
+		"added header": {
+			first:  httpClientConfig{},
+			second: httpClientConfig{Headers: map[string]string{"X-Team": "coffee"}},
+		},
+		"different proxy": {
+			first:  httpClientConfig{},
+			second: httpClientConfig{ProxyURL: proxyURL},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			registry := newSessionRegistry()
+
+			// Only the session key matters, so both clients send requests
+			// directly to the fake API.
+			first, err := registry.client(context.Background(), api.URL(), fakeAPIUsername, fakeAPIPassword, testCase.first, newHTTPClient(httpClientConfig{}))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			second, err := registry.client(context.Background(), api.URL(), fakeAPIUsername, fakeAPIPassword, testCase.second, newHTTPClient(httpClientConfig{}))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if shared := first.Token == second.Token; shared != testCase.expectShared {
+				t.Errorf("expected shared session %t, got tokens %q and %q", testCase.expectShared, first.Token, second.Token)
+			}
+		})
+	}
+}
+
+func TestSessionRegistry_ClientCredentials(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	registry := newSessionRegistry()
+
+	// A failed sign-in is not remembered.
+	if _, err := registry.client(context.Background(), api.URL(), fakeAPIUsername, "wrong", httpClientConfig{}, newHTTPClient(httpClientConfig{})); err == nil {
+		t.Fatal("expected error signing in with the wrong password")
+	}
+
+	if _, err := registry.client(context.Background(), api.URL(), fakeAPIUsername, fakeAPIPassword, httpClientConfig{}, newHTTPClient(httpClientConfig{})); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The session of another password is not reused.
+	if _, err := registry.client(context.Background(), api.URL(), fakeAPIUsername, "wrong", httpClientConfig{}, newHTTPClient(httpClientConfig{})); err == nil {
+		t.Fatal("expected error signing in with the wrong password")
+	}
+
+	if got := api.requestCount("POST /signin"); got != 3 {
+		t.Errorf("expected 3 sign-ins, got %d", got)
+	}
+}
+
+func TestSessionRegistry_ClientConcurrent(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+	registry := newSessionRegistry()
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 10)
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := registry.client(context.Background(), api.URL(), fakeAPIUsername, fakeAPIPassword, httpClientConfig{}, newHTTPClient(httpClientConfig{}))
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	}
+
+	if got := api.requestCount("POST /signin"); got != 1 {
+		t.Errorf("expected 1 sign-in, got %d", got)
+	}
+}
+
+func TestProvider_SharedSession(t *testing.T) {
+	api := newFakeHashicupsAPI(t)
+
+	// Like aliased providers for the same HashiCups instance.
+	primary := newTestProviderServer(t, map[string]any{"host": api.URL()})
+	secondary := newTestProviderServer(t, map[string]any{"host": api.URL(), "user_agent": "secondary"})
+
+	if got := api.requestCount("POST /signin"); got != 1 {
+		t.Errorf("expected 1 sign-in, got %d", got)
+	}
+
+	for _, server := range []*testProviderServer{primary, secondary} {
+		created := server.create("hashicups_order", orderConfig(1, 1))
+		server.requireNoErrors(created.Diagnostics)
+	}
+}
+
+func TestTransportRegistry_Transport(t *testing.T) {
+	registry := newTransportRegistry()
+
+	proxyURL, err := url.Parse("http://proxy.example.com:3128")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if registry.transport(nil) != registry.transport(nil) {
+		t.Error("expected clients without a proxy to share a transport")
+	}
+
+	if registry.transport(proxyURL) != registry.transport(proxyURL) {
+		t.Error("expected clients with the same proxy to share a transport")
+	}
+
+	if registry.transport(proxyURL) == registry.transport(nil) {
+		t.Error("expected clients with different proxies not to share a transport")
+	}
+}
@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// minRequestsPerSecond and maxRequestsPerSecond bound the request rate.
+	// Slower rates would stall Terraform operations for minutes per
+	// request, and faster rates round to no delay between requests.
+	minRequestsPerSecond = 0.01
+	maxRequestsPerSecond = 1000
+)
+
+// throttleTransport limits the rate and concurrency of requests sent to the
+// HashiCups API. Terraform walks the graph in parallel, so without it a
+// large configuration can overwhelm a small API instance.
+type throttleTransport struct {
+	// interval is the minimum time between the start of two requests. Zero
+	// disables rate limiting.
+	interval time.Duration
+
+	// slots holds one token per request in flight. It is nil when the
+	// number of concurrent requests is not limited.
+	slots chan struct{}
+
+	mu       sync.Mutex
+	nextSend time.Time
+
+	next http.RoundTripper
+}
+
+// newThrottleTransport returns next unchanged when neither limit is set.
+func newThrottleTransport(requestsPerSecond float64, maxConcurrentRequests int, next http.RoundTripper) http.RoundTripper {
+	if requestsPerSecond <= 0 && maxConcurrentRequests <= 0 {
+		return next
+	}
+
+	t := &throttleTransport{
+		next: next,
+	}
+
+	if requestsPerSecond > 0 {
+		// The provider validates the rate, but clamp it anyway, as the
+		// interval overflows for tiny rates and is zero, which disables
+		// rate limiting, for huge ones.
+		requestsPerSecond = min(max(requestsPerSecond, minRequestsPerSecond), maxRequestsPerSecond)
+
+		t.interval = time.Duration(float64(time.Second) / requestsPerSecond)
+	}
+
+	if maxConcurrentRequests > 0 {
+		t.slots = make(chan struct{}, maxConcurrentRequests)
+	}
+
+	return t
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *throttleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		default:
+			tflog.SubsystemDebug(ctx, apiLogSubsystem, "Waiting for a free HashiCups API request slot", map[string]any{
+				"max_concurrent_requests": cap(t.slots),
+				"http_method":             req.Method,
+				"http_path":               req.URL.Path,
+			})
+
+			select {
+			case t.slots <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	if delay := t.reserve(); delay > 0 {
+		tflog.SubsystemDebug(ctx, apiLogSubsystem, "Throttling HashiCups API request", map[string]any{
+			"delay":       delay.String(),
+			"http_method": req.Method,
+			"http_path":   req.URL.Path,
+		})
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			t.release()
+			return nil, ctx.Err()
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.release()
+		return nil, err
+	}
+
+	// Hold the slot until the response body has been read.
+	resp.Body = newCloseFuncBody(resp.Body, t.release)
+
+	return resp, nil
+}
+
+// reserve claims the next send time and returns how long the caller must
+// wait before sending.
+func (t *throttleTransport) reserve() time.Duration {
+	if t.interval == 0 {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if t.nextSend.Before(now) {
+		t.nextSend = now
+	}
+
+	delay := t.nextSend.Sub(now)
+	t.nextSend = t.nextSend.Add(t.interval)
+
+	return delay
+}
+
+// release frees the request slot, if any.
+func (t *throttleTransport) release() {
+	if t.slots != nil {
+		<-t.slots
+	}
+}
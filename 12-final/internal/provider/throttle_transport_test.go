@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestThrottleTransport_MaxConcurrentRequests(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := newHTTPClient(httpClientConfig{
+		MaxConcurrentRequests: 2,
+	})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+
+	wg.Wait()
+
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", maxInFlight)
+	}
+}
+
+func TestThrottleTransport_RequestsPerSecond(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := newHTTPClient(httpClientConfig{
+		RequestsPerSecond: 20,
+	})
+
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		resp.Body.Close()
+	}
+
+	// The first request is sent immediately and each following request
+	// waits for 50ms.
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected requests to be throttled, took %s", elapsed)
+	}
+}
+
+func TestNewThrottleTransport_Interval(t *testing.T) {
+	testCases := map[string]struct {
+		requestsPerSecond float64
+		expected          time.Duration
+	}{
+		"rate": {
+			requestsPerSecond: 20,
+			expected:          50 * time.Millisecond,
+		},
+		"tiny": {
+			requestsPerSecond: 1e-12,
+			expected:          100 * time.Second,
+		},
+		"huge": {
+			requestsPerSecond: 1e12,
+			expected:          time.Millisecond,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			transport, ok := newThrottleTransport(testCase.requestsPerSecond, 0, http.DefaultTransport).(*throttleTransport)
+			if !ok {
+				t.Fatal("expected throttle transport")
+			}
+
+			if transport.interval != testCase.expected {
+				t.Errorf("expected interval %s, got %s", testCase.expected, transport.interval)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the tracer, and the default service name, of
+// the provider.
+const tracerName = "terraform-provider-hashicups"
+
+// InitTracing configures OpenTelemetry tracing from the standard OTEL_*
+// environment variables, and returns a function which flushes any pending
+// spans and stops tracing. Tracing is opt-in: OTEL_TRACES_EXPORTER selects
+// the exporter.
+//
+//   - "otlp" sends spans over OTLP to the endpoint configured with
+//     OTEL_EXPORTER_OTLP_ENDPOINT and the related variables. Spans are sent
+//     over HTTP, unless OTEL_EXPORTER_OTLP_TRACES_PROTOCOL or
+//     OTEL_EXPORTER_OTLP_PROTOCOL is "grpc".
+//   - "file" appends spans as JSON to the file named by
+//     HASHICUPS_OTEL_TRACES_FILE. Terraform reserves the standard output of
+//     providers, so spans cannot be written to the console.
+//   - "none", or no value, disables tracing.
+//
+// OTEL_SERVICE_NAME, OTEL_RESOURCE_ATTRIBUTES and OTEL_TRACES_SAMPLER are
+// also honoured.
+func InitTracing(ctx context.Context, version string) (func(context.Context) error, error) {
+	var processor sdktrace.SpanProcessor
+	var closeFile func() error
+
+	switch exporterName := os.Getenv("OTEL_TRACES_EXPORTER"); exporterName {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		protocol, err := otlpTracesProtocol()
+		if err != nil {
+			return nil, err
+		}
+
+		var exporter sdktrace.SpanExporter
+
+		switch protocol {
+		case "grpc":
+			exporter, err = otlptracegrpc.New(ctx)
+		default:
+			exporter, err = otlptracehttp.New(ctx)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("error creating OTLP trace exporter: %w", err)
+		}
+
+		processor = sdktrace.NewBatchSpanProcessor(exporter)
+	case "file":
+		name := os.Getenv("HASHICUPS_OTEL_TRACES_FILE")
+		if name == "" {
+			return nil, errors.New("HASHICUPS_OTEL_TRACES_FILE must be set when OTEL_TRACES_EXPORTER is \"file\"")
+		}
+
+		file, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("error opening trace file: %w", err)
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("error creating file trace exporter: %w", err)
+		}
+
+		// Write each span as it ends, so spans are not lost if Terraform
+		// stops the provider before it can flush them.
+		processor = sdktrace.NewSimpleSpanProcessor(exporter)
+		closeFile = file.Close
+	default:
+		return nil, fmt.Errorf("unsupported OTEL_TRACES_EXPORTER %q, expected \"otlp\", \"file\" or \"none\"", exporterName)
+	}
+
+	// Later options take precedence, so the environment can override the
+	// service name.
+	res, err := sdkresource.New(ctx,
+		sdkresource.WithAttributes(
+			attribute.String("service.name", tracerName),
+			attribute.String("service.version", version),
+		),
+		sdkresource.WithTelemetrySDK(),
+		sdkresource.WithFromEnv(),
+	)
+	if err != nil {
+		err = errors.Join(err, processor.Shutdown(ctx))
+
+		if closeFile != nil {
+			err = errors.Join(err, closeFile())
+		}
+
+		return nil, fmt.Errorf("error creating trace resource: %w", err)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return func(ctx context.Context) error {
+		err := tracerProvider.Shutdown(ctx)
+
+		if closeFile != nil {
+			err = errors.Join(err, closeFile())
+		}
+
+		return err
+	}, nil
+}
+
+// otlpTracesProtocol returns the protocol the OTLP exporter sends spans
+// with, "grpc" or "http/protobuf", from OTEL_EXPORTER_OTLP_TRACES_PROTOCOL,
+// or else OTEL_EXPORTER_OTLP_PROTOCOL. The "http/json" protocol is not
+// supported by the OpenTelemetry Go exporters, so it is rejected rather
+// than silently sending protobuf.
+func otlpTracesProtocol() (string, error) {
+	name := "OTEL_EXPORTER_OTLP_TRACES_PROTOCOL"
+
+	protocol := os.Getenv(name)
+	if protocol == "" {
+		name = "OTEL_EXPORTER_OTLP_PROTOCOL"
+		protocol = os.Getenv(name)
+	}
+
+	switch protocol {
+	case "", "http/protobuf":
+		return "http/protobuf", nil
+	case "grpc":
+		return protocol, nil
+	default:
+		return "", fmt.Errorf("unsupported %s %q, expected \"grpc\" or \"http/protobuf\"", name, protocol)
+	}
+}
+
+// tracer returns the tracer of the provider. It is looked up on each use,
+// so spans go to the tracer provider installed by InitTracing, or are
+// discarded when tracing is disabled.
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// startSpan starts a span for a provider operation, such as
+// "hashicups_order.Create".
+func startSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// endSpan ends span, setting its status from the error diagnostics of the
+// operation, if any.
+func endSpan(span trace.Span, diags diag.Diagnostics) {
+	if errs := diags.Errors(); len(errs) > 0 {
+		span.SetStatus(codes.Error, errs[0].Summary())
+	}
+
+	span.End()
+}
+
+// tracingTransport creates a client span for each HashiCups API request,
+// and propagates the trace context to the API. The span covers any
+// throttling and retries, and ends once the response body is closed.
+type tracingTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := tracer().Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Host),
+			attribute.String("url.path", req.URL.Path),
+		),
+	)
+
+	// RoundTrip must not modify the original request.
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+	// Client spans of error responses are errors, and their error type is
+	// the status code.
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetAttributes(attribute.String("error.type", strconv.Itoa(resp.StatusCode)))
+		span.SetStatus(codes.Error, resp.Status)
+	}
+
+	resp.Body = newCloseFuncBody(resp.Body, func() { span.End() })
+
+	return resp, nil
+}
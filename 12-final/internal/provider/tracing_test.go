@@ -0,0 +1,353 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp-demoapp/hashicups-client-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newTestTracing records the spans of the provider in memory for the rest
+// of the test.
+func newTestTracing(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	originalTracerProvider := otel.GetTracerProvider()
+	originalPropagator := otel.GetTextMapPropagator()
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(originalTracerProvider)
+		otel.SetTextMapPropagator(originalPropagator)
+		_ = tracerProvider.Shutdown(context.Background())
+	})
+
+	return exporter
+}
+
+// findSpan returns the first recorded span with the given name.
+func findSpan(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+
+	return findChildSpan(t, spans, nil, name)
+}
+
+// findChildSpan returns the first recorded span with the given name whose
+// parent is parent, or any span with the name if parent is nil.
+func findChildSpan(t *testing.T, spans tracetest.SpanStubs, parent *tracetest.SpanStub, name string) tracetest.SpanStub {
+	t.Helper()
+
+	for _, span := range spans {
+		if span.Name != name {
+			continue
+		}
+
+		if parent == nil || span.Parent.SpanID() == parent.SpanContext.SpanID() {
+			return span
+		}
+	}
+
+	var names []string
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+
+	t.Fatalf("expected span %q, got: %v", name, names)
+
+	return tracetest.SpanStub{}
+}
+
+// spanAttribute returns the value of the given span attribute.
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+func TestOrderResource_Tracing(t *testing.T) {
+	exporter := newTestTracing(t)
+	api := newFakeHashicupsAPI(t)
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	created := server.create("hashicups_order", orderConfig(1, 2))
+	server.requireNoErrors(created.Diagnostics)
+
+	read := server.read("hashicups_order", created.NewState, created.NewIdentity)
+	server.requireNoErrors(read.Diagnostics)
+
+	updated := server.update("hashicups_order", read.NewState, orderConfig(2, 1))
+	server.requireNoErrors(updated.Diagnostics)
+
+	deleted := server.delete("hashicups_order", updated.NewState)
+	server.requireNoErrors(deleted.Diagnostics)
+
+	spans := exporter.GetSpans()
+
+	for _, name := range []string{"hashicups_order.Create", "hashicups_order.Read", "hashicups_order.Update", "hashicups_order.Delete"} {
+		span := findSpan(t, spans, name)
+
+		if got := spanAttribute(span, "hashicups.order.id").AsString(); got != "1" {
+			t.Errorf("expected %s order ID %q, got %q", name, "1", got)
+		}
+
+		if span.Status.Code != codes.Unset {
+			t.Errorf("expected %s status %s, got %s", name, codes.Unset, span.Status.Code)
+		}
+	}
+
+	create := findSpan(t, spans, "hashicups_order.Create")
+	post := findChildSpan(t, spans, &create, "HTTP POST")
+
+	expected := map[attribute.Key]attribute.Value{
+		"http.request.method":       attribute.StringValue("POST"),
+		"url.path":                  attribute.StringValue("/orders"),
+		"server.address":            attribute.StringValue(strings.TrimPrefix(api.URL(), "http://")),
+		"http.response.status_code": attribute.IntValue(http.StatusOK),
+	}
+
+	for key, value := range expected {
+		if got := spanAttribute(post, key); got != value {
+			t.Errorf("expected %s %v, got %v", key, value.Emit(), got.Emit())
+		}
+	}
+}
+
+func TestOrderResource_TracingError(t *testing.T) {
+	exporter := newTestTracing(t)
+	api := newFakeHashicupsAPI(t)
+	api.handle("POST /orders", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "database unavailable", http.StatusInternalServerError)
+	})
+	server := newTestProviderServer(t, map[string]any{"host": api.URL()})
+
+	resp := server.create("hashicups_order", orderConfig(1, 2))
+	server.requireError(resp.Diagnostics, "Error creating order")
+
+	spans := exporter.GetSpans()
+
+	create := findSpan(t, spans, "hashicups_order.Create")
+
+	if create.Status.Code != codes.Error || create.Status.Description != "Error creating order" {
+		t.Errorf("expected Create span status %s %q, got %s %q", codes.Error, "Error creating order", create.Status.Code, create.Status.Description)
+	}
+
+	post := findChildSpan(t, spans, &create, "HTTP POST")
+
+	if post.Status.Code != codes.Error {
+		t.Errorf("expected HTTP POST span status %s, got %s", codes.Error, post.Status.Code)
+	}
+
+	if got := spanAttribute(post, "http.response.status_code").AsInt64(); got != http.StatusInternalServerError {
+		t.Errorf("expected status code %d, got %d", http.StatusInternalServerError, got)
+	}
+
+	if got := spanAttribute(post, "error.type").AsString(); got != "500" {
+		t.Errorf("expected error type %q, got %q", "500", got)
+	}
+}
+
+func TestTracingTransport_Propagation(t *testing.T) {
+	exporter := newTestTracing(t)
+
+	var traceparent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := &hashicups.Client{
+		HostURL:    server.URL,
+		HTTPClient: newHTTPClient(httpClientConfig{}),
+	}
+
+	ctx, span := startSpan(context.Background(), "test")
+
+	_, err := clientWithContext(ctx, client).GetCoffees()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	span.End()
+
+	get := findSpan(t, exporter.GetSpans(), "HTTP GET")
+
+	if !strings.Contains(traceparent, get.SpanContext.TraceID().String()) || !strings.Contains(traceparent, get.SpanContext.SpanID().String()) {
+		t.Errorf("expected traceparent header for the HTTP GET span, got %q", traceparent)
+	}
+}
+
+func TestInitTracing(t *testing.T) {
+	originalTracerProvider := otel.GetTracerProvider()
+	originalPropagator := otel.GetTextMapPropagator()
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(originalTracerProvider)
+		otel.SetTextMapPropagator(originalPropagator)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Setenv("OTEL_TRACES_EXPORTER", "none")
+
+		shutdown, err := InitTracing(context.Background(), "test")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if otel.GetTracerProvider() != originalTracerProvider {
+			t.Error("expected tracer provider to be unchanged")
+		}
+
+		if err := shutdown(context.Background()); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("file", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "traces.json")
+
+		t.Setenv("OTEL_TRACES_EXPORTER", "file")
+		t.Setenv("OTEL_SERVICE_NAME", "hashicups-test")
+		t.Setenv("HASHICUPS_OTEL_TRACES_FILE", name)
+
+		shutdown, err := InitTracing(context.Background(), "test")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		_, span := startSpan(context.Background(), "hashicups_order.Create")
+		span.End()
+
+		if err := shutdown(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		contents, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		for _, expected := range []string{`"Name":"hashicups_order.Create"`, `"Value":"hashicups-test"`} {
+			if !strings.Contains(string(contents), expected) {
+				t.Errorf("expected trace file to contain %s, got: %s", expected, contents)
+			}
+		}
+	})
+
+	t.Run("file without path", func(t *testing.T) {
+		t.Setenv("OTEL_TRACES_EXPORTER", "file")
+		t.Setenv("HASHICUPS_OTEL_TRACES_FILE", "")
+
+		_, err := InitTracing(context.Background(), "test")
+		if err == nil || !strings.Contains(err.Error(), "HASHICUPS_OTEL_TRACES_FILE") {
+			t.Errorf("expected missing file error, got: %v", err)
+		}
+	})
+
+	t.Run("otlp grpc", func(t *testing.T) {
+		t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+		t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc")
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4317")
+
+		shutdown, err := InitTracing(context.Background(), "test")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if otel.GetTracerProvider() == originalTracerProvider {
+			t.Error("expected tracer provider to be installed")
+		}
+
+		// No spans were started, so nothing is sent to the collector.
+		if err := shutdown(context.Background()); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("unsupported otlp protocol", func(t *testing.T) {
+		t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+		t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "http/json")
+
+		_, err := InitTracing(context.Background(), "test")
+		if err == nil || !strings.Contains(err.Error(), `unsupported OTEL_EXPORTER_OTLP_TRACES_PROTOCOL "http/json"`) {
+			t.Errorf("expected unsupported protocol error, got: %v", err)
+		}
+	})
+
+	t.Run("unsupported exporter", func(t *testing.T) {
+		t.Setenv("OTEL_TRACES_EXPORTER", "zipkin")
+
+		_, err := InitTracing(context.Background(), "test")
+		if err == nil || !strings.Contains(err.Error(), `unsupported OTEL_TRACES_EXPORTER "zipkin"`) {
+			t.Errorf("expected unsupported exporter error, got: %v", err)
+		}
+	})
+}
+
+func TestOTLPTracesProtocol(t *testing.T) {
+	testCases := map[string]struct {
+		protocol       string
+		tracesProtocol string
+		expected       string
+		expectError    bool
+	}{
+		"default": {
+			expected: "http/protobuf",
+		},
+		"grpc": {
+			protocol: "grpc",
+			expected: "grpc",
+		},
+		"traces grpc": {
+			protocol:       "http/protobuf",
+			tracesProtocol: "grpc",
+			expected:       "grpc",
+		},
+		"traces http": {
+			protocol:       "grpc",
+			tracesProtocol: "http/protobuf",
+			expected:       "http/protobuf",
+		},
+		"json": {
+			protocol:    "http/json",
+			expectError: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", testCase.protocol)
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", testCase.tracesProtocol)
+
+			got, err := otlpTracesProtocol()
+			if testCase.expectError != (err != nil) {
+				t.Fatalf("expected error %t, got: %v", testCase.expectError, err)
+			}
+
+			if got != testCase.expected {
+				t.Errorf("expected protocol %q, got %q", testCase.expected, got)
+			}
+		})
+	}
+}
@@ -48,7 +48,23 @@ func main() {
 		Debug:   debug,
 	}
 
-	err := providerserver.Serve(context.Background(), provider.New(version), opts)
+	ctx := context.Background()
+
+	// Tracing is a diagnostic aid, so a tracing misconfiguration must not
+	// stop the provider from serving Terraform.
+	shutdownTracing, err := provider.InitTracing(ctx, version)
+	if err != nil {
+		log.Printf("tracing disabled: %s", err)
+
+		shutdownTracing = func(context.Context) error { return nil }
+	}
+
+	err = providerserver.Serve(ctx, provider.New(version), opts)
+
+	// Flush spans before exiting, as log.Fatal skips deferred calls.
+	if shutdownErr := shutdownTracing(ctx); shutdownErr != nil {
+		log.Printf("error shutting down tracing: %s", shutdownErr)
+	}
 
 	if err != nil {
 		log.Fatal(err.Error())